@@ -0,0 +1,71 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// rekt-janitor deletes the test namespaces left behind by failed runs once
+// their TTL expired, along with the cluster-scoped resources with the
+// reconciler-test labels referencing them. It can run from a CronJob.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/injection"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/janitor"
+)
+
+func main() {
+	olderThan := flag.Duration("older-than", 0, "Delete the test namespaces older than this duration, ignoring their TTL")
+	defaultTTL := flag.Duration("default-ttl", environment.DefaultNamespaceTTL, "TTL of the test namespaces without the "+environment.NamespaceTTLAnnotation+" annotation")
+	dryRun := flag.Bool("dry-run", false, "List what would be deleted without deleting anything")
+	output := flag.String("output", "text", "Output `format`, text or json")
+
+	cfg := injection.ParseAndGetRESTConfigOrDie()
+	if *output != "text" && *output != "json" {
+		log.Fatalf("Unknown output format %q, expected text or json", *output)
+	}
+
+	j := janitor.New(kubernetes.NewForConfigOrDie(cfg), dynamic.NewForConfigOrDie(cfg), janitor.Options{
+		OlderThan:  *olderThan,
+		DefaultTTL: *defaultTTL,
+		DryRun:     *dryRun,
+	})
+	report, err := j.Run(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(report.Errors) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,404 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"knative.dev/reconciler-test/pkg/feature"
+)
+
+// configFileEnv is the environment variable used as the default of the
+// rekt.config flag.
+const configFileEnv = "REKT_CONFIG"
+
+// Config is the content of the file passed with --rekt.config, each setting
+// is the default of the flag of the same name, so flags passed on the command
+// line take precedence over the file. Unset settings keep the flag default.
+//
+//	feature: [alpha, beta]
+//	requirement: [must, should]
+//	poll:
+//	  interval: 2s
+//	  timeout: 4m
+//	profiles:
+//	  conformance:
+//	    requirement: [must]
+//	    feature: [stable]
+type Config struct {
+	// Feature are the feature states to test, as the feature.<state> flags,
+	// any selects all the states.
+	Feature []string `json:"feature,omitempty"`
+	// Requirement are the requirement levels to test, as the
+	// requirement.<level> flags, all selects all the levels.
+	Requirement []string `json:"requirement,omitempty"`
+	// FeatureMatch is the feature flag.
+	FeatureMatch *string `json:"featureMatch,omitempty"`
+	// FeatureTags is the feature.tags flag.
+	FeatureTags *string `json:"featureTags,omitempty"`
+	// Repeat holds the feature.repeat flags.
+	Repeat *RepeatConfig `json:"repeat,omitempty"`
+	// Namespace is the environment.namespace flag.
+	Namespace *string `json:"namespace,omitempty"`
+	// Images holds the images flags.
+	Images *ImagesConfig `json:"images,omitempty"`
+	// Poll holds the poll flags.
+	Poll *PollConfig `json:"poll,omitempty"`
+	// StepTimeout is the step.timeout flag.
+	StepTimeout *metav1.Duration `json:"stepTimeout,omitempty"`
+	// TeardownOnFail is the teardown.on.fail flag.
+	TeardownOnFail *bool `json:"teardownOnFail,omitempty"`
+	// Istio holds the istio flags.
+	Istio *IstioFileConfig `json:"istio,omitempty"`
+	// Rekt holds the rekt flags.
+	Rekt *RektConfig `json:"rekt,omitempty"`
+}
+
+// RepeatConfig holds the feature.repeat flags.
+type RepeatConfig struct {
+	Count              *int  `json:"count,omitempty"`
+	UntilFail          *bool `json:"untilFail,omitempty"`
+	SeparateNamespaces *bool `json:"separateNamespaces,omitempty"`
+}
+
+// ImagesConfig holds the images flags.
+type ImagesConfig struct {
+	// ProducerFile is the images.producer.file flag.
+	ProducerFile *string `json:"producerFile,omitempty"`
+}
+
+// PollConfig holds the poll flags.
+type PollConfig struct {
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	Timeout  *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// IstioFileConfig holds the istio flags.
+type IstioFileConfig struct {
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// RektConfig holds the rekt flags.
+type RektConfig struct {
+	DryRun              *bool            `json:"dryRun,omitempty"`
+	LeakCheck           *string          `json:"leakCheck,omitempty"`
+	SnapshotOnFail      *bool            `json:"snapshotOnFail,omitempty"`
+	UpgradePhase        *string          `json:"upgradePhase,omitempty"`
+	Seed                *int64           `json:"seed,omitempty"`
+	ConformanceReport   *string          `json:"conformanceReport,omitempty"`
+	Shard               *string          `json:"shard,omitempty"`
+	ShardTimings        *string          `json:"shardTimings,omitempty"`
+	MaxParallelFeatures *int             `json:"maxParallelFeatures,omitempty"`
+	NamespaceTTL        *metav1.Duration `json:"namespaceTTL,omitempty"`
+	// NamespacePool holds the rekt.namespace-pool flags.
+	NamespacePool *NamespacePoolConfig `json:"namespacePool,omitempty"`
+}
+
+// NamespacePoolConfig holds the rekt.namespace-pool flags.
+type NamespacePoolConfig struct {
+	Size  *int    `json:"size,omitempty"`
+	Reuse *string `json:"reuse,omitempty"`
+}
+
+// Reserved keys of the configuration file, which aren't settings.
+const (
+	configProfileKey  = "profile"
+	configProfilesKey = "profiles"
+)
+
+type configSectionsKey struct{}
+
+// WithConfigSection is a ConfigurationOption to decode the top level section
+// name of the --rekt.config file, and of the selected profile, into the
+// pointer section, so that embedders can keep their own settings in the same
+// file. The section is left untouched when the file doesn't have it.
+func WithConfigSection(name string, section interface{}) ConfigurationOption {
+	return func(configuration Configuration) Configuration {
+		sections, _ := configuration.Context.Value(configSectionsKey{}).(map[string]interface{})
+		merged := make(map[string]interface{}, len(sections)+1)
+		for k, v := range sections {
+			merged[k] = v
+		}
+		merged[name] = section
+		configuration.Context = context.WithValue(configuration.Context, configSectionsKey{}, merged)
+		return configuration
+	}
+}
+
+// LoadConfig reads the configuration file at path, applies the profile, or
+// the profile selected in the file when empty, and decodes the sections
+// registered with WithConfigSection found in ctx.
+func LoadConfig(ctx context.Context, path, profile string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the configuration file: %w", err)
+	}
+	cfg, err := parseConfig(ctx, b, profile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func parseConfig(ctx context.Context, b []byte, profile string) (*Config, error) {
+	doc := make(map[string]interface{})
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	profiles, ok := doc[configProfilesKey].(map[string]interface{})
+	if _, found := doc[configProfilesKey]; found && !ok {
+		return nil, fmt.Errorf("%s must be a map of profile names to settings", configProfilesKey)
+	}
+	if profile == "" {
+		profile, _ = doc[configProfileKey].(string)
+	}
+	delete(doc, configProfilesKey)
+	delete(doc, configProfileKey)
+
+	if profile != "" {
+		p, ok := profiles[profile].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q, known profiles are %v", profile, profileNames(profiles))
+		}
+		mergeSettings(doc, p)
+	}
+
+	sections, _ := ctx.Value(configSectionsKey{}).(map[string]interface{})
+	for name, section := range sections {
+		raw, ok := doc[name]
+		if !ok {
+			continue
+		}
+		delete(doc, name)
+		if err := convert(raw, section, false); err != nil {
+			return nil, fmt.Errorf("section %s: %w", name, err)
+		}
+	}
+
+	cfg := &Config{}
+	if err := convert(doc, cfg, true); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// mergeSettings merges the settings of a profile into the settings of the
+// file, the nested maps are merged while the other values are replaced.
+func mergeSettings(into, from map[string]interface{}) {
+	for k, v := range from {
+		vm, ok := v.(map[string]interface{})
+		im, iok := into[k].(map[string]interface{})
+		if ok && iok {
+			mergeSettings(im, vm)
+			continue
+		}
+		into[k] = v
+	}
+}
+
+// convert decodes the generic value in into the typed out, rejecting the
+// unknown fields when strict.
+func convert(in, out interface{}, strict bool) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(out)
+}
+
+func profileNames(profiles map[string]interface{}) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// flagValue is the value of a flag set from the configuration file.
+type flagValue struct {
+	name  string
+	value string
+}
+
+// flags returns the values of the flags set by the configuration, in the
+// order in which they have to be set.
+func (c *Config) flags() []flagValue {
+	var fvs []flagValue
+	add := func(name string, v interface{}) {
+		switch v := v.(type) {
+		case *string:
+			if v != nil {
+				fvs = append(fvs, flagValue{name, *v})
+			}
+		case *bool:
+			if v != nil {
+				fvs = append(fvs, flagValue{name, strconv.FormatBool(*v)})
+			}
+		case *int:
+			if v != nil {
+				fvs = append(fvs, flagValue{name, strconv.Itoa(*v)})
+			}
+		case *int64:
+			if v != nil {
+				fvs = append(fvs, flagValue{name, strconv.FormatInt(*v, 10)})
+			}
+		case *metav1.Duration:
+			if v != nil {
+				fvs = append(fvs, flagValue{name, v.Duration.String()})
+			}
+		}
+	}
+
+	// The state and level flags toggle bits, so everything is cleared before
+	// the configured ones are set.
+	if len(c.Feature) > 0 {
+		fvs = append(fvs, flagValue{"feature.any", "false"})
+		for _, name := range c.Feature {
+			fvs = append(fvs, flagValue{"feature." + flagSuffix(name), "true"})
+		}
+	}
+	if len(c.Requirement) > 0 {
+		fvs = append(fvs, flagValue{"requirement.all", "false"})
+		for _, name := range c.Requirement {
+			fvs = append(fvs, flagValue{"requirement." + flagSuffix(name), "true"})
+		}
+	}
+
+	add("feature", c.FeatureMatch)
+	add("feature.tags", c.FeatureTags)
+	if c.Repeat != nil {
+		add("feature.repeat", c.Repeat.Count)
+		add("feature.repeat.until-fail", c.Repeat.UntilFail)
+		add("feature.repeat.separate-namespaces", c.Repeat.SeparateNamespaces)
+	}
+	add("environment.namespace", c.Namespace)
+	if c.Images != nil {
+		add("images.producer.file", c.Images.ProducerFile)
+	}
+	if c.Poll != nil {
+		add("poll.interval", c.Poll.Interval)
+		add("poll.timeout", c.Poll.Timeout)
+	}
+	add("step.timeout", c.StepTimeout)
+	add("teardown.on.fail", c.TeardownOnFail)
+	if c.Istio != nil {
+		add("istio.enabled", c.Istio.Enabled)
+	}
+	if c.Rekt != nil {
+		add("rekt.dry-run", c.Rekt.DryRun)
+		add("rekt.leak-check", c.Rekt.LeakCheck)
+		add("rekt.snapshot-on-fail", c.Rekt.SnapshotOnFail)
+		add("rekt.upgrade.phase", c.Rekt.UpgradePhase)
+		add("rekt.seed", c.Rekt.Seed)
+		add("rekt.conformance-report", c.Rekt.ConformanceReport)
+		add("rekt.shard", c.Rekt.Shard)
+		add("rekt.shard.timings", c.Rekt.ShardTimings)
+		add("rekt.max-parallel-features", c.Rekt.MaxParallelFeatures)
+		add("rekt.namespace-ttl", c.Rekt.NamespaceTTL)
+		if c.Rekt.NamespacePool != nil {
+			add("rekt.namespace-pool.size", c.Rekt.NamespacePool.Size)
+			add("rekt.namespace-pool.reuse", c.Rekt.NamespacePool.Reuse)
+		}
+	}
+	return fvs
+}
+
+// flagSuffix returns the flag suffix of a state or level name, for example
+// mustnot for "MUST NOT".
+func flagSuffix(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), " ", "")
+}
+
+// Apply sets the flags of fs from the configuration, except the flags already
+// set on the command line. The feature.<state> and requirement.<level> flags
+// are a group each: setting one of them on the command line ignores the
+// corresponding list of the configuration.
+func (c *Config) Apply(fs *flag.FlagSet) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+		switch {
+		case isStateFlag(f.Name):
+			set["feature.any"] = true
+		case strings.HasPrefix(f.Name, "requirement."):
+			set["requirement.all"] = true
+		}
+	})
+
+	for _, fv := range c.flags() {
+		group := fv.name
+		switch {
+		case isStateFlag(fv.name):
+			group = "feature.any"
+		case strings.HasPrefix(fv.name, "requirement."):
+			group = "requirement.all"
+		}
+		if set[fv.name] || set[group] {
+			continue
+		}
+		if fs.Lookup(fv.name) == nil {
+			return fmt.Errorf("unknown setting for flag %s", fv.name)
+		}
+		if err := fs.Set(fv.name, fv.value); err != nil {
+			return fmt.Errorf("invalid value %q for flag %s: %w", fv.value, fv.name, err)
+		}
+	}
+	return nil
+}
+
+// isStateFlag returns whether name is one of the feature.<state> flags.
+func isStateFlag(name string) bool {
+	if name == "feature.any" {
+		return true
+	}
+	for _, state := range feature.StatesMapping {
+		if name == "feature."+flagSuffix(state) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyConfigFile loads the --rekt.config file, if any, and applies it to the
+// flags that weren't set on the command line.
+func applyConfigFile(ctx context.Context, fs *flag.FlagSet) error {
+	if configFile == nil || *configFile == "" {
+		return nil
+	}
+	cfg, err := LoadConfig(ctx, *configFile, *configProfile)
+	if err != nil {
+		return err
+	}
+	return cfg.Apply(fs)
+}
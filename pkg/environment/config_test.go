@@ -0,0 +1,133 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"knative.dev/reconciler-test/pkg/feature"
+)
+
+const testConfig = `
+feature: [alpha, beta]
+requirement: [must, should]
+featureTags: "!slow"
+poll:
+  interval: 2s
+  timeout: 4m
+istio:
+  enabled: true
+rekt:
+  maxParallelFeatures: 3
+eventing:
+  broker: MTChannelBasedBroker
+profiles:
+  conformance:
+    requirement: [must]
+    feature: [stable]
+    poll:
+      timeout: 10m
+    eventing:
+      broker: Kafka
+`
+
+func TestConfigFile(t *testing.T) {
+	type eventingSection struct {
+		Broker string `json:"broker"`
+	}
+
+	tests := map[string]struct {
+		args    []string
+		states  feature.States
+		levels  feature.Levels
+		timeout time.Duration
+		broker  string
+		wantErr string
+	}{
+		"file": {
+			args:    []string{},
+			states:  feature.Alpha | feature.Beta,
+			levels:  feature.Must | feature.Should,
+			timeout: 4 * time.Minute,
+			broker:  "MTChannelBasedBroker",
+		},
+		"profile": {
+			args:    []string{"--rekt.config.profile=conformance"},
+			states:  feature.Stable,
+			levels:  feature.Must,
+			timeout: 10 * time.Minute,
+			broker:  "Kafka",
+		},
+		"flags take precedence": {
+			args:    []string{"--rekt.config.profile=conformance", "--poll.timeout=1m", "--requirement.may"},
+			states:  feature.Stable,
+			levels:  feature.All,
+			timeout: time.Minute,
+			broker:  "Kafka",
+		},
+		"unknown profile": {
+			args:    []string{"--rekt.config.profile=nope"},
+			wantErr: `unknown profile "nope", known profiles are [conformance]`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Cleanup(func() {
+				// Restore the flag defaults.
+				InitFlags(flag.NewFlagSet("defaults", flag.ContinueOnError))
+			})
+			path := filepath.Join(t.TempDir(), "rekt.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(testConfig), 0644))
+
+			fs := flag.NewFlagSet(name, flag.ContinueOnError)
+			InitFlags(fs)
+			istio := &IstioConfig{}
+			fs.BoolVar(&istio.Enabled, "istio.enabled", false, "")
+			require.NoError(t, fs.Parse(append(tc.args, "--rekt.config="+path)))
+
+			section := &eventingSection{}
+			ctx := WithConfigSection("eventing", section)(Configuration{Context: context.Background()}).Context
+			err := applyConfigFile(ctx, fs)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, tc.states, *s)
+			require.Equal(t, tc.levels, *l)
+			require.Equal(t, tc.timeout, *pollTimeout)
+			require.Equal(t, 2*time.Second, *pollInterval)
+			require.Equal(t, "!slow", *featureTags)
+			require.Equal(t, 3, *maxParallelFeatures)
+			require.True(t, istio.Enabled)
+			require.Equal(t, tc.broker, section.Broker)
+		})
+	}
+}
+
+func TestConfigFileUnknownSetting(t *testing.T) {
+	_, err := parseConfig(context.Background(), []byte("pol:\n  timeout: 1m\n"), "")
+	require.ErrorContains(t, err, `unknown field "pol"`)
+}
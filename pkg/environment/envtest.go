@@ -0,0 +1,174 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"knative.dev/reconciler-test/pkg/feature"
+)
+
+const (
+	crdPollInterval = 100 * time.Millisecond
+	crdPollTimeout  = 30 * time.Second
+)
+
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// ControlPlane is a local control plane that can be started and stopped on
+// demand, such as the *envtest.Environment of
+// sigs.k8s.io/controller-runtime/pkg/envtest.
+type ControlPlane interface {
+	Start() (*rest.Config, error)
+	Stop() error
+}
+
+// NewEnvtestGlobalEnvironment starts testEnv, a local etcd and kube-apiserver
+// usually managed by controller-runtime's envtest, installs the
+// CustomResourceDefinitions found in crdDirectory and creates a standard
+// GlobalEnvironment using it.
+//
+// The envtest binaries are located through the KUBEBUILDER_ASSETS environment
+// variable and webhooks served locally can be registered through the
+// WebhookInstallOptions of the envtest.Environment. testEnv is stopped when an
+// error is returned, otherwise the caller is responsible for stopping it once
+// the tests are done.
+//
+// Since no controller manager runs, the default ServiceAccount of the test
+// namespaces is created by the environment, resources are deleted with
+// background propagation, and deleted namespaces stay terminating.
+func NewEnvtestGlobalEnvironment(testEnv ControlPlane, crdDirectory string, opts ...ConfigurationOption) (GlobalEnvironment, error) {
+	cfg, err := testEnv.Start()
+	if err != nil {
+		return nil, stopControlPlane(testEnv, fmt.Errorf("failed to start envtest: %w", err))
+	}
+
+	if crdDirectory != "" {
+		if err := installCRDs(context.Background(), cfg, crdDirectory); err != nil {
+			return nil, stopControlPlane(testEnv, err)
+		}
+	}
+
+	opts = append(opts, func(c Configuration) Configuration {
+		c.Context = withDefaultServiceAccount(c.Context)
+		c.Context = feature.ContextWithDeletePropagation(c.Context, metav1.DeletePropagationBackground)
+		return c
+	})
+	return NewStandardGlobalEnvironmentWithRestConfig(cfg, opts...), nil
+}
+
+// stopControlPlane stops testEnv, which may have been partially started, and
+// returns err along with the error stopping it, if any.
+func stopControlPlane(testEnv ControlPlane, err error) error {
+	if stopErr := testEnv.Stop(); stopErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to stop envtest: %w", stopErr))
+	}
+	return err
+}
+
+// installCRDs creates the CustomResourceDefinitions of the YAML and JSON files
+// in dir and waits for them to be established.
+func installCRDs(ctx context.Context, cfg *rest.Config, dir string) error {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read CRD directory %s: %w", dir, err)
+	}
+
+	client, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	crds := client.Resource(crdResource)
+
+	var names []string
+	for _, file := range files {
+		switch filepath.Ext(file.Name()) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		objs, err := decodeCRDs(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return err
+		}
+		for _, obj := range objs {
+			if _, err := crds.Create(ctx, obj, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to create CRD %s: %w", obj.GetName(), err)
+			}
+			names = append(names, obj.GetName())
+		}
+	}
+
+	for _, name := range names {
+		err := wait.PollUntilContextTimeout(ctx, crdPollInterval, crdPollTimeout, true, func(ctx context.Context) (bool, error) {
+			crd, err := crds.Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return false, nil
+			}
+			conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+			for _, c := range conditions {
+				if m, ok := c.(map[string]interface{}); ok && m["type"] == "Established" && m["status"] == "True" {
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+		if err != nil {
+			return fmt.Errorf("CRD %s was not established: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func decodeCRDs(path string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var objs []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		if obj.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/feature"
+	testlog "knative.dev/reconciler-test/pkg/logging"
+)
+
+// envtestControlPlane is the ControlPlane of a controller-runtime envtest
+// Environment, only the tests depend on controller-runtime.
+type envtestControlPlane struct {
+	env *envtest.Environment
+}
+
+func (c envtestControlPlane) Start() (*rest.Config, error) {
+	return c.env.Start()
+}
+
+func (c envtestControlPlane) Stop() error {
+	return c.env.Stop()
+}
+
+type testFlags struct {
+	fs *flag.FlagSet
+}
+
+func (f testFlags) Get(context.Context) *flag.FlagSet {
+	return f.fs
+}
+
+func (f testFlags) Parse(context.Context) error {
+	return f.fs.Parse(nil)
+}
+
+func TestEnvtestGlobalEnvironment(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set")
+	}
+
+	testEnv := envtestControlPlane{env: &envtest.Environment{}}
+	global, err := environment.NewEnvtestGlobalEnvironment(testEnv, "testdata/envtest",
+		func(c environment.Configuration) environment.Configuration {
+			c.Flags = testFlags{fs: flag.NewFlagSet(t.Name(), flag.ContinueOnError)}
+			c.Context = testlog.WithTestLogger(c.Context, t)
+			return c
+		},
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, testEnv.Stop())
+	})
+	ctx, env := global.Environment()
+
+	kube := kubeclient.Get(ctx)
+	ns, err := kube.CoreV1().Namespaces().Get(ctx, env.Namespace(), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "reconciler-test", ns.Labels["app.kubernetes.io/name"])
+	_, err = kube.CoreV1().ServiceAccounts(env.Namespace()).Get(ctx, "default", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	f := feature.NewFeature()
+	f.Setup("install widget", install("Widget", "w"))
+	f.Teardown("delete resources", f.DeleteResources)
+
+	env.Test(ctx, t, f)
+	require.Len(t, env.References(), 1)
+
+	_, err = dynamicclient.Get(ctx).Resource(widgets).Namespace(env.Namespace()).Get(ctx, "w", metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), err)
+
+	env.Finish()
+
+	// Without a namespace controller the namespace is never removed, it stays
+	// terminating.
+	ns, err = kube.CoreV1().Namespaces().Get(ctx, env.Namespace(), metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		require.NoError(t, err)
+		require.NotNil(t, ns.DeletionTimestamp)
+	}
+}
+
+// controlPlane is a ControlPlane whose server is never reachable.
+type controlPlane struct {
+	startErr error
+	stopErr  error
+	stopped  int
+}
+
+func (c *controlPlane) Start() (*rest.Config, error) {
+	if c.startErr != nil {
+		return nil, c.startErr
+	}
+	return &rest.Config{Host: "http://127.0.0.1:1"}, nil
+}
+
+func (c *controlPlane) Stop() error {
+	c.stopped++
+	return c.stopErr
+}
+
+func TestEnvtestGlobalEnvironmentStopsOnError(t *testing.T) {
+	tests := map[string]struct {
+		testEnv *controlPlane
+		want    []string
+	}{
+		"start fails": {
+			testEnv: &controlPlane{startErr: errors.New("etcd not found")},
+			want:    []string{"failed to start envtest: etcd not found"},
+		},
+		"CRDs fail": {
+			testEnv: &controlPlane{},
+			want:    []string{"failed to create CRD widgets.example.dev"},
+		},
+		"stop fails": {
+			testEnv: &controlPlane{stopErr: errors.New("kill failed")},
+			want:    []string{"failed to create CRD widgets.example.dev", "failed to stop envtest: kill failed"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := environment.NewEnvtestGlobalEnvironment(tc.testEnv, "testdata/envtest")
+			for _, want := range tc.want {
+				require.ErrorContains(t, err, want)
+			}
+			require.Equal(t, 1, tc.testEnv.stopped)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"knative.dev/reconciler-test/pkg/feature"
 )
@@ -28,50 +29,270 @@ func filterStepTimings(steps []feature.Step, timing feature.Timing) []feature.St
 	return res
 }
 
-func (mr *MagicEnvironment) executeOptional(ctx context.Context, t *testing.T, f *feature.Feature, s *feature.Step, aggregator *stepExecutionAggregator) {
+// executeSteps executes the steps of a single timing in parallel sub tests, so
+// that -test.parallel bounds them. A step that declares dependencies on other
+// steps is skipped if any of them failed or was skipped.
+//
+// The steps with dependencies, or that other steps depend on, run first, each
+// as soon as its dependencies are done, in sub tests started from their own
+// goroutines within the -test.parallel slot of the timing: a parallel sub test
+// only starts once the calling test function returned and it would hold a
+// -test.parallel slot while waiting for its dependencies.
+//
+// With --rekt.max-parallel-features, the remaining steps run in sub tests
+// started from their own goroutines too: the features waiting for a feature
+// slot hold -test.parallel slots, which parallel steps would then wait for
+// forever.
+func (mr *MagicEnvironment) executeSteps(ctx context.Context, t *testing.T, f *feature.Feature, steps feature.Steps, aggregator *stepExecutionAggregator) {
+	t.Helper()
+	mr.executeGraph(ctx, t, f, steps, aggregator, true)
+}
+
+// executeStepsInOrder executes the steps of a single timing one at a time, in
+// dependency order.
+func (mr *MagicEnvironment) executeStepsInOrder(ctx context.Context, t *testing.T, f *feature.Feature, steps feature.Steps, aggregator *stepExecutionAggregator) {
 	t.Helper()
-	mr.executeStep(ctx, t, f, s, createSkippingT, aggregator)
+	mr.executeGraph(ctx, t, f, steps, aggregator, false)
 }
 
-// execute executes the step in a sub test without wrapping t.
-func (mr *MagicEnvironment) execute(ctx context.Context, t *testing.T, f *feature.Feature, s *feature.Step, aggregator *stepExecutionAggregator) {
+// executeDeferredTeardown executes the steps deferred with DeferTeardown one at
+// a time, last deferred first, in a DeferredTeardown sub test, until no step is
+// left: the deferred steps may defer more steps. It runs even when a previous
+// timing failed, once the other teardown steps completed.
+func (mr *MagicEnvironment) executeDeferredTeardown(ctx context.Context, t *testing.T, f *feature.Feature, aggregator *stepExecutionAggregator) {
 	t.Helper()
-	mr.executeStep(ctx, t, f, s, func(t *testing.T) feature.T { return t }, aggregator)
+
+	deferred := f.TakeDeferredTeardown()
+	if len(deferred) == 0 {
+		return
+	}
+	t.Run("DeferredTeardown", func(t *testing.T) {
+		for ; len(deferred) > 0; deferred = f.TakeDeferredTeardown() {
+			mr.executeStepsInOrder(ctx, t, f, deferred, aggregator)
+		}
+	})
 }
 
-func (mr *MagicEnvironment) executeStep(ctx context.Context, t *testing.T, f *feature.Feature, s *feature.Step, tDecorator func(t *testing.T) feature.T, aggregator *stepExecutionAggregator) {
+func (mr *MagicEnvironment) executeGraph(ctx context.Context, t *testing.T, f *feature.Feature, steps feature.Steps, aggregator *stepExecutionAggregator, parallel bool) {
 	t.Helper()
 
-	t.Run(s.Name, func(t *testing.T) {
-		t.Parallel()
-		t.Helper()
-		ft := tDecorator(t)
-		t.Cleanup(func() {
-			mr.milestones.StepFinished(f.Name, s, ft)
+	deps, err := steps.DependencyGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inGraph := make([]bool, len(steps))
+	for i, ds := range deps {
+		for _, d := range ds {
+			inGraph[i] = true
+			inGraph[d] = true
+		}
+	}
+
+	// done is closed once the step completed, succeeded is set before.
+	done := make([]chan struct{}, len(steps))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	succeeded := make([]bool, len(steps))
+	run := func(i int, parallel bool) {
+		defer close(done[i])
+		s := steps[i]
+		t.Run(s.Name, func(t *testing.T) {
+			if parallel {
+				t.Parallel()
+			}
+			t.Helper()
+
+			for _, d := range deps[i] {
+				if !succeeded[d] {
+					aggregator.AddFailed(&s)
+					t.Skipf("Skipping step, dependency %q did not succeed", steps[d].Name)
+				}
+			}
+
+			if mr.shouldFail(&s) && s.T != feature.Prerequisite {
+				succeeded[i] = mr.execute(ctx, t, f, &s, aggregator)
+			} else {
+				succeeded[i] = mr.executeOptional(ctx, t, f, &s, aggregator)
+			}
 		})
+	}
 
-		// Create a cancel tied to this step
-		internalCtx, internalCancelFn := context.WithCancel(ctx)
+	if !parallel {
+		for _, i := range topologicalOrder(deps) {
+			run(i, false)
+		}
+		return
+	}
 
-		defer func() {
-			if r := recover(); r != nil {
-				ft.Errorf("Panic happened: '%v'", r)
+	concurrent := func(i int) bool {
+		return inGraph[i] || mr.featureSlots != nil
+	}
+	var wg sync.WaitGroup
+	for i := range steps {
+		if !concurrent(i) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, d := range deps[i] {
+				<-done[d]
 			}
-			// Close the context as soon as possible (this defer is invoked before the Cleanup)
-			internalCancelFn()
+			run(i, false)
 		}()
+	}
+	wg.Wait()
 
-		mr.milestones.StepStarted(f.Name, s, ft)
+	for i := range steps {
+		if !concurrent(i) {
+			run(i, true)
+		}
+	}
+}
 
-		// Perform step.
-		s.Fn(internalCtx, ft)
+// topologicalOrder returns the indexes of the steps so that each step comes
+// after its dependencies, keeping the declaration order otherwise.
+func topologicalOrder(deps [][]int) []int {
+	order := make([]int, 0, len(deps))
+	visited := make([]bool, len(deps))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		for _, d := range deps[i] {
+			visit(d)
+		}
+		order = append(order, i)
+	}
+	for i := range deps {
+		visit(i)
+	}
+	return order
+}
+
+func (mr *MagicEnvironment) executeOptional(ctx context.Context, t *testing.T, f *feature.Feature, s *feature.Step, aggregator *stepExecutionAggregator) bool {
+	t.Helper()
+	return mr.executeStep(ctx, t, f, s, createSkippingT, aggregator)
+}
+
+// execute executes the step without wrapping t.
+func (mr *MagicEnvironment) execute(ctx context.Context, t *testing.T, f *feature.Feature, s *feature.Step, aggregator *stepExecutionAggregator) bool {
+	t.Helper()
+	return mr.executeStep(ctx, t, f, s, func(t *testing.T) feature.T { return t }, aggregator)
+}
+
+// executeStep executes the step in the sub test t and reports whether it
+// completed without failing or being skipped.
+func (mr *MagicEnvironment) executeStep(ctx context.Context, t *testing.T, f *feature.Feature, s *feature.Step, tDecorator func(t *testing.T) feature.T, aggregator *stepExecutionAggregator) (succeeded bool) {
+	t.Helper()
+	ft := tDecorator(t)
+	t.Cleanup(func() {
+		mr.milestones.StepFinished(f.Name, s, ft)
+	})
+
+	// Create a cancel tied to this step
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = mr.stepTimeout
+	}
+	internalCtx, internalCancelFn := withStepTimeout(ctx, timeout)
 
+	// The deferred functions run even when the step or a hook calls
+	// t.FailNow, in reverse order.
+	defer func() {
+		// Close the context as soon as possible (this defer is invoked before the Cleanup)
+		internalCancelFn()
+	}()
+	defer func() {
 		if ft.Failed() {
 			aggregator.AddFailed(s)
 		} else {
 			aggregator.AddSucceeded(s)
+			succeeded = !ft.Skipped()
 		}
-	})
+	}()
+	defer func() {
+		// The context of the step is done once it timed out, the hooks get
+		// their own, with the same timeout, to inspect what went wrong.
+		hookCtx, cancel := withStepTimeout(ctx, timeout)
+		defer cancel()
+		mr.afterStep(hookCtx, ft, f, s)
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			ft.Errorf("Panic happened: '%v'", r)
+		}
+	}()
+
+	mr.milestones.StepStarted(f.Name, s, ft)
+
+	if !mr.beforeStep(internalCtx, ft, f, s) {
+		return
+	}
+
+	// Perform step.
+	if timeout > 0 {
+		performWithTimeout(ctx, internalCtx, ft, f, s, timeout)
+	} else {
+		s.Fn(internalCtx, ft)
+	}
+	return
+}
+
+// withStepTimeout returns a context derived from ctx expiring after the
+// timeout, if any.
+func withStepTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// performWithTimeout runs the step in its own goroutine, so that the step can
+// be failed once its context expires, even if the step function doesn't
+// return. FailNow and SkipNow are called back on the test goroutine once the
+// step function returned. In that case the feature references are logged to help debugging
+// what the step was waiting for.
+func performWithTimeout(ctx, stepCtx context.Context, ft feature.T, f *feature.Feature, s *feature.Step, timeout time.Duration) {
+	deadline, _ := stepCtx.Deadline()
+	tt := newTimeoutT(ft, deadline)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				tt.Errorf("Panic happened: '%v'", r)
+			}
+		}()
+		s.Fn(stepCtx, tt)
+	}()
+
+	select {
+	case <-done:
+		tt.stopIfFatal()
+		return
+	case <-stepCtx.Done():
+	}
+
+	// From now on, the step function can't use the step T anymore since the
+	// test might complete before the step function returns.
+	tt.close()
+
+	select {
+	case <-done:
+		// The step completed right at the deadline.
+		tt.stopIfFatal()
+		return
+	default:
+	}
+
+	ft.Errorf("Step %q timed out after %s", s.Name, timeout)
+	feature.LogReferences(f.References()...)(ctx, ft)
 }
 
 // stepExecutionAggregator aggregates various parallel steps results.
@@ -0,0 +1,414 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/milestone"
+)
+
+func TestExecuteStepsInDependencyOrder(t *testing.T) {
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:          feature.All,
+			s:          feature.Any,
+			milestones: milestone.Compose(),
+		},
+	}
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) feature.StepFn {
+		return func(ctx context.Context, t feature.T) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+		}
+	}
+
+	f := feature.NewFeature()
+	f.Setup("trigger", record("trigger"), feature.After("broker"))
+	f.Setup("source", record("source"), feature.After("trigger", "sink"))
+	f.Setup("broker", record("broker"))
+	f.Setup("sink", record("sink"))
+
+	aggregator := newStepExecutionAggregator()
+	t.Run("Setup", func(t *testing.T) {
+		mr.executeSteps(context.Background(), t, f, f.Steps, aggregator)
+	})
+
+	require.Len(t, order, 4)
+	index := func(name string) int {
+		for i, n := range order {
+			if n == name {
+				return i
+			}
+		}
+		return -1
+	}
+	require.Less(t, index("broker"), index("trigger"))
+	require.Less(t, index("trigger"), index("source"))
+	require.Less(t, index("sink"), index("source"))
+	require.Empty(t, aggregator.Failed())
+}
+
+func TestExecuteStepsInParallelSubTests(t *testing.T) {
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:          feature.All,
+			s:          feature.Any,
+			milestones: milestone.Compose(),
+		},
+	}
+
+	var ran []string
+	var mu sync.Mutex
+	record := func(name string) feature.StepFn {
+		return func(ctx context.Context, t feature.T) {
+			mu.Lock()
+			defer mu.Unlock()
+			ran = append(ran, name)
+		}
+	}
+
+	f := feature.NewFeature()
+	f.Setup("broker", record("broker"))
+	f.Setup("trigger", record("trigger"), feature.After("broker"))
+	f.Setup("sink", record("sink"))
+
+	aggregator := newStepExecutionAggregator()
+	t.Run("Setup", func(t *testing.T) {
+		mr.executeSteps(context.Background(), t, f, f.Steps, aggregator)
+
+		// Only the steps with dependencies ran, the others are parallel sub
+		// tests waiting for this function to return.
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, []string{"broker", "trigger"}, ran)
+	})
+
+	require.ElementsMatch(t, []string{"broker", "trigger", "sink"}, ran)
+}
+
+func TestExecuteReadyStepsInParallel(t *testing.T) {
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:          feature.All,
+			s:          feature.Any,
+			milestones: milestone.Compose(),
+		},
+	}
+
+	// broker and sink only complete once both started.
+	started := make(chan struct{}, 2)
+	both := func(ctx context.Context, t feature.T) {
+		started <- struct{}{}
+		deadline := time.After(5 * time.Second)
+		for len(started) < 2 {
+			select {
+			case <-deadline:
+				t.Fatal("the steps without dependencies didn't run in parallel")
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}
+	triggered := false
+	f := feature.NewFeature()
+	f.Setup("broker", both)
+	f.Setup("sink", both)
+	f.Setup("trigger", func(ctx context.Context, t feature.T) {
+		triggered = true
+	}, feature.After("broker", "sink"))
+
+	aggregator := newStepExecutionAggregator()
+	t.Run("Setup", func(t *testing.T) {
+		mr.executeSteps(context.Background(), t, f, f.Steps, aggregator)
+	})
+
+	require.True(t, triggered)
+	require.Empty(t, aggregator.Failed())
+}
+
+func TestExecuteStepsSkipsDependentsOfFailedStep(t *testing.T) {
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:          feature.All,
+			s:          feature.Any,
+			milestones: milestone.Compose(),
+		},
+	}
+
+	ran := false
+	f := feature.NewFeature()
+	f.Prerequisite("broker", func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		return feature.PrerequisiteResult{ShouldRun: false, Reason: "no broker"}, nil
+	})
+	f.Prerequisite("trigger", func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		ran = true
+		return feature.PrerequisiteResult{ShouldRun: true}, nil
+	}, feature.After("broker"))
+
+	aggregator := newStepExecutionAggregator()
+	t.Run("Prerequisite", func(t *testing.T) {
+		mr.executeSteps(context.Background(), t, f, f.Steps, aggregator)
+	})
+
+	require.False(t, ran)
+	// The skipped dependent is reported as well.
+	require.ElementsMatch(t, []string{"broker", "trigger"}, stepNames(aggregator.Failed()))
+}
+
+func stepNames(steps []*feature.Step) []string {
+	names := make([]string, 0, len(steps))
+	for _, s := range steps {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestExecuteStepTimeout(t *testing.T) {
+	// The hooks get a context that is not done with the step.
+	hookErrs := make(map[string]error)
+	hook := func(name string) StepHookFn {
+		return func(ctx context.Context, t feature.T, f *feature.Feature, s *feature.Step) {
+			hookErrs[name] = ctx.Err()
+		}
+	}
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:           feature.All,
+			s:           feature.Any,
+			milestones:  milestone.Compose(),
+			stepTimeout: time.Hour,
+			stepHooks: []StepHooks{{
+				AfterStep: hook("after"),
+				OnFailure: hook("failure"),
+			}},
+		},
+	}
+
+	returned := make(chan struct{})
+	var deadline time.Time
+	f := feature.NewFeature()
+	f.Prerequisite("hangs", func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		defer close(returned)
+		deadline, _ = t.Deadline()
+		<-ctx.Done()
+		time.Sleep(100 * time.Millisecond)
+		// The step has timed out and the test might be completed already.
+		t.Log("late log")
+		return feature.PrerequisiteResult{ShouldRun: true}, nil
+	}, feature.Timeout(50*time.Millisecond))
+
+	aggregator := newStepExecutionAggregator()
+	start := time.Now()
+	t.Run("Prerequisite", func(t *testing.T) {
+		mr.executeSteps(context.Background(), t, f, f.Steps, aggregator)
+	})
+	<-returned
+
+	require.Len(t, aggregator.Failed(), 1)
+	require.WithinDuration(t, start.Add(50*time.Millisecond), deadline, 40*time.Millisecond)
+	require.Equal(t, map[string]error{"after": nil, "failure": nil}, hookErrs)
+}
+
+func TestRunFeatureSkipsByTags(t *testing.T) {
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:           feature.All,
+			s:           feature.Any,
+			featureTags: feature.MustParseTagExpression("tls && !slow"),
+			milestones:  milestone.Compose(),
+		},
+	}
+
+	ran := false
+	f := feature.NewFeature()
+	f.Tags("tls", "slow")
+	f.Setup("step", func(ctx context.Context, t feature.T) {
+		ran = true
+	})
+
+	mr.runFeature(context.Background(), t, f)
+
+	require.False(t, ran)
+}
+
+func TestRunFeatureSkipsGroupsByTags(t *testing.T) {
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:            feature.All,
+			s:            feature.Any,
+			featureMatch: regexp.MustCompile(""),
+			featureTags:  feature.MustParseTagExpression("tls && !slow"),
+			milestones:   milestone.Compose(),
+			stateStore:   newKVStore,
+		},
+	}
+
+	var ran []string
+	f := feature.NewFeatureNamed("Grouped")
+	f.Tags("tls")
+	f.Group("fast", func(f *feature.Feature) {
+		f.Setup("step", func(ctx context.Context, t feature.T) {
+			ran = append(ran, "fast")
+		})
+	})
+	f.Group("slow", func(f *feature.Feature) {
+		f.Tags("slow")
+		f.Setup("step", func(ctx context.Context, t feature.T) {
+			ran = append(ran, "slow")
+		})
+	})
+
+	mr.runFeature(context.Background(), t, f)
+
+	require.Equal(t, []string{"fast"}, ran)
+}
+
+func TestDeferTeardownDuringTeardown(t *testing.T) {
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:            feature.All,
+			s:            feature.Any,
+			featureMatch: regexp.MustCompile(""),
+			milestones:   milestone.Compose(),
+			stateStore:   newKVStore,
+		},
+	}
+
+	var mu sync.Mutex
+	var order []string
+	record := func(step string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, step)
+	}
+	f := feature.NewFeatureNamed("Deferred")
+	f.Teardown("delete", func(ctx context.Context, t feature.T) {
+		feature.FromContext(ctx).DeferTeardown("undo", func(ctx context.Context, t feature.T) {
+			record("undo")
+			feature.FromContext(ctx).DeferTeardown("undo the undo", func(ctx context.Context, t feature.T) {
+				record("undo the undo")
+			})
+		})
+		record("delete")
+	})
+	f.Teardown("slow delete", func(ctx context.Context, t feature.T) {
+		time.Sleep(20 * time.Millisecond)
+		record("slow delete")
+	})
+	mr.runFeature(context.Background(), t, f)
+
+	require.Len(t, order, 4)
+	// The teardown steps run in parallel, the deferred steps after them.
+	require.ElementsMatch(t, []string{"delete", "slow delete"}, order[:2])
+	require.Equal(t, []string{"undo", "undo the undo"}, order[2:])
+	require.Empty(t, f.TakeDeferredTeardown())
+}
+
+const (
+	deferTeardownHelperEnv  = "REKT_TEST_DEFER_TEARDOWN_OUTPUT"
+	teardownOnFailHelperEnv = "REKT_TEST_TEARDOWN_ON_FAIL"
+)
+
+// TestDeferTeardownHelper runs a feature deferring teardown steps and then
+// failing. It fails by design and only runs as a sub process of
+// TestDeferTeardownRunsOnFailure.
+func TestDeferTeardownHelper(t *testing.T) {
+	output := os.Getenv(deferTeardownHelperEnv)
+	if output == "" {
+		t.Skip("Only runs as a sub process of TestDeferTeardownRunsOnFailure")
+	}
+
+	var order []string
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:              feature.All,
+			s:              feature.Any,
+			featureMatch:   regexp.MustCompile(""),
+			milestones:     milestone.Compose(),
+			stateStore:     newKVStore,
+			teardownOnFail: os.Getenv(teardownOnFailHelperEnv) != "",
+			stepHooks: []StepHooks{{
+				BeforeStep: func(ctx context.Context, t feature.T, f *feature.Feature, s *feature.Step) {
+					if s.Name == "Log references" {
+						order = append(order, "logged")
+					}
+				},
+			}},
+		},
+	}
+
+	f := feature.NewFeatureNamed("Deferred")
+	f.Setup("change the cluster", func(ctx context.Context, t feature.T) {
+		for _, name := range []string{"first", "second"} {
+			name := name
+			feature.FromContext(ctx).DeferTeardown("undo "+name, func(ctx context.Context, t feature.T) {
+				order = append(order, name)
+			})
+		}
+	})
+	f.Assert("fails", func(ctx context.Context, t feature.T) {
+		t.Error("Failing on purpose")
+	})
+	f.Teardown("not deferred", func(ctx context.Context, t feature.T) {
+		order = append(order, "not deferred")
+	})
+	mr.runFeature(context.Background(), t, f)
+
+	require.NoError(t, os.WriteFile(output, []byte(strings.Join(order, ",")), 0o644))
+}
+
+func TestDeferTeardownRunsOnFailure(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "order")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDeferTeardownHelper$")
+	cmd.Env = append(os.Environ(), deferTeardownHelperEnv+"="+output)
+	out, err := cmd.CombinedOutput()
+	require.Error(t, err, "the helper test fails:\n%s", out)
+
+	order, err := os.ReadFile(output)
+	require.NoError(t, err, string(out))
+	// Without --teardown.on.fail, only the logging and deferred steps run,
+	// last deferred first.
+	require.Equal(t, "logged,second,first", string(order))
+}
+
+func TestTeardownOnFailLogsFirst(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "order")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestDeferTeardownHelper$")
+	cmd.Env = append(os.Environ(), deferTeardownHelperEnv+"="+output, teardownOnFailHelperEnv+"=true")
+	out, err := cmd.CombinedOutput()
+	require.Error(t, err, "the helper test fails:\n%s", out)
+
+	order, err := os.ReadFile(output)
+	require.NoError(t, err, string(out))
+	// The references are logged before any teardown step deletes them.
+	require.Equal(t, "logged,not deferred,second,first", string(order))
+}
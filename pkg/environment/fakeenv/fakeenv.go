@@ -0,0 +1,253 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakeenv creates environments backed by client-go fake clients, to
+// test features with plain `go test` and without a cluster.
+package fakeenv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sscheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+
+	"knative.dev/reconciler-test/pkg/environment"
+)
+
+const (
+	// pollInterval and pollTimeout are the default poll timings of a fake
+	// environment, since fake clients never have to wait for the system to
+	// converge.
+	pollInterval = 10 * time.Millisecond
+	pollTimeout  = 5 * time.Second
+)
+
+// StatusFunc simulates what a controller would do to the status of an
+// object created with the fake dynamic client.
+type StatusFunc func(obj *unstructured.Unstructured) error
+
+// Option configures the fake clients of a GlobalEnvironment created with
+// NewGlobalEnvironment.
+type Option func(fc *fakeClients)
+
+type fakeClients struct {
+	kubeObjects    []runtime.Object
+	dynamicObjects []runtime.Object
+	scheme         *runtime.Scheme
+	listKinds      map[schema.GroupVersionResource]string
+	statusFuncs    map[schema.GroupVersionResource][]StatusFunc
+	seed           int64
+
+	namespacePoolSize  int
+	namespacePoolReuse environment.NamespacePoolReuse
+}
+
+// WithKubeObjects adds objects to the fake Kubernetes clientset.
+func WithKubeObjects(objs ...runtime.Object) Option {
+	return func(fc *fakeClients) {
+		fc.kubeObjects = append(fc.kubeObjects, objs...)
+	}
+}
+
+// WithDynamicObjects adds objects to the fake dynamic client.
+func WithDynamicObjects(objs ...runtime.Object) Option {
+	return func(fc *fakeClients) {
+		fc.dynamicObjects = append(fc.dynamicObjects, objs...)
+	}
+}
+
+// WithListKinds registers the list kinds of resources that are not part
+// of the client-go scheme, so that they can be listed with the fake dynamic
+// client, for example:
+//
+//	WithListKinds(map[schema.GroupVersionResource]string{
+//		{Group: "eventing.knative.dev", Version: "v1", Resource: "brokers"}: "BrokerList",
+//	})
+func WithListKinds(listKinds map[schema.GroupVersionResource]string) Option {
+	return func(fc *fakeClients) {
+		for gvr, kind := range listKinds {
+			fc.listKinds[gvr] = kind
+		}
+	}
+}
+
+// WithStatus registers functions that are applied to every object of the
+// given resource created with the fake dynamic client, for example to mark it
+// as ready with Ready.
+func WithStatus(gvr schema.GroupVersionResource, fns ...StatusFunc) Option {
+	return func(fc *fakeClients) {
+		fc.statusFuncs[gvr] = append(fc.statusFuncs[gvr], fns...)
+	}
+}
+
+// WithSeed seeds the random names of the environments, as the rekt.seed
+// flag does, without replacing the feature.DefaultRand.
+func WithSeed(seed int64) Option {
+	return func(fc *fakeClients) {
+		fc.seed = seed
+	}
+}
+
+// WithNamespacePool creates the namespaces of the environments ahead of
+// them, as the rekt.namespace-pool flags do.
+func WithNamespacePool(size int, reuse environment.NamespacePoolReuse) Option {
+	return func(fc *fakeClients) {
+		fc.namespacePoolSize = size
+		fc.namespacePoolReuse = reuse
+	}
+}
+
+// Conditions returns a StatusFunc that sets the given status
+// conditions.
+func Conditions(conditions ...apis.Condition) StatusFunc {
+	return func(obj *unstructured.Unstructured) error {
+		return setConditions(obj, conditions...)
+	}
+}
+
+// Ready returns a StatusFunc that sets the Ready condition to True.
+func Ready() StatusFunc {
+	return Conditions(apis.Condition{
+		Type:   apis.ConditionReady,
+		Status: corev1.ConditionTrue,
+	})
+}
+
+// NewGlobalEnvironment creates a GlobalEnvironment backed by client-go fake
+// clients, registered in the context under the same injection keys as the real
+// ones, so that features can be tested with plain `go test` and without a
+// cluster.
+//
+// The Kubernetes clientset and the dynamic client don't share their objects,
+// resources installed from YAML manifests are only visible through the dynamic
+// client. Nothing reconciles the objects either, their status can be simulated
+// with WithStatus or SetConditions.
+func NewGlobalEnvironment(ctx context.Context, opts ...Option) environment.GlobalEnvironment {
+	fc := &fakeClients{
+		scheme:      runtime.NewScheme(),
+		listKinds:   make(map[schema.GroupVersionResource]string),
+		statusFuncs: make(map[schema.GroupVersionResource][]StatusFunc),
+	}
+	if err := k8sscheme.AddToScheme(fc.scheme); err != nil {
+		panic(err)
+	}
+	for _, opt := range opts {
+		opt(fc)
+	}
+
+	ctx, _ = fakekubeclient.With(ctx, fc.kubeObjects...)
+
+	objs := make([]runtime.Object, 0, len(fc.dynamicObjects))
+	for _, obj := range fc.dynamicObjects {
+		objs = append(objs, obj.DeepCopyObject())
+	}
+	dc := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(fc.scheme, fc.listKinds, objs...)
+	dc.PrependReactor("create", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		fns := fc.statusFuncs[action.GetResource()]
+		if len(fns) == 0 {
+			return false, nil, nil
+		}
+		// Don't modify the object of the caller.
+		create := action.DeepCopy().(clienttesting.CreateAction)
+		obj, ok := create.GetObject().(*unstructured.Unstructured)
+		if !ok {
+			return false, nil, nil
+		}
+		for _, fn := range fns {
+			if err := fn(obj); err != nil {
+				return true, nil, err
+			}
+		}
+		return clienttesting.ObjectReaction(dc.Tracker())(create)
+	})
+	ctx = context.WithValue(ctx, dynamicclient.Key{}, dc)
+
+	return environment.NewLocalGlobalEnvironment(ctx, environment.LocalConfig{
+		PollInterval:                pollInterval,
+		PollTimeout:                 pollTimeout,
+		Seed:                        fc.seed,
+		NamespacePoolSize:           fc.namespacePoolSize,
+		NamespacePoolReuse:          fc.namespacePoolReuse,
+		CreateDefaultServiceAccount: true,
+	})
+}
+
+// SetConditions sets the status conditions of an object of the fake
+// dynamic client found in the context, as a controller would do, for example
+// to drive k8s.IsReady from a test.
+func SetConditions(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string, conditions ...apis.Condition) error {
+	client := dynamicclient.Get(ctx).Resource(gvr).Namespace(namespace)
+	obj, err := client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if err := setConditions(obj, conditions...); err != nil {
+		return err
+	}
+	_, err = client.UpdateStatus(ctx, obj, metav1.UpdateOptions{})
+	return err
+}
+
+// setConditions sets the status conditions of obj, replacing the existing
+// conditions of the same type, and marks the generation as observed.
+func setConditions(obj *unstructured.Unstructured, conditions ...apis.Condition) error {
+	existing, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil {
+		return fmt.Errorf("failed to get the conditions of %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	for _, c := range conditions {
+		if c.LastTransitionTime.Inner.IsZero() {
+			c.LastTransitionTime = apis.VolatileTime{Inner: metav1.Now()}
+		}
+		b, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		condition := make(map[string]interface{})
+		if err := json.Unmarshal(b, &condition); err != nil {
+			return err
+		}
+
+		replaced := false
+		for i, e := range existing {
+			if m, ok := e.(map[string]interface{}); ok && m["type"] == string(c.Type) {
+				existing[i] = condition
+				replaced = true
+			}
+		}
+		if !replaced {
+			existing = append(existing, condition)
+		}
+	}
+
+	if err := unstructured.SetNestedSlice(obj.Object, existing, "status", "conditions"); err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(obj.Object, obj.GetGeneration(), "status", "observedGeneration")
+}
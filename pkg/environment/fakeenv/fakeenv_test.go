@@ -0,0 +1,140 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeenv_test
+
+import (
+	"context"
+	"embed"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/environment/fakeenv"
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/feature/featuretest"
+	"knative.dev/reconciler-test/pkg/k8s"
+	testlog "knative.dev/reconciler-test/pkg/logging"
+	"knative.dev/reconciler-test/pkg/manifest"
+)
+
+//go:embed testdata/*.yaml
+var templates embed.FS
+
+var (
+	widgets = schema.GroupVersionResource{Group: "example.dev", Version: "v1", Resource: "widgets"}
+	gadgets = schema.GroupVersionResource{Group: "example.dev", Version: "v1", Resource: "gadgets"}
+)
+
+func install(kind, name string) feature.StepFn {
+	return func(ctx context.Context, t feature.T) {
+		if _, err := manifest.InstallYamlFS(ctx, templates, map[string]interface{}{
+			"kind": kind,
+			"name": name,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWithSeed(t *testing.T) {
+	names := func() []string {
+		global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t),
+			fakeenv.WithSeed(42),
+		)
+		var names []string
+		for i := 0; i < 2; i++ {
+			ctx, env := global.Environment()
+			names = append(names, env.Namespace(), feature.RandFromContext(ctx).MakeRandomK8sName("sink"))
+			env.Finish()
+		}
+		return names
+	}
+
+	first := names()
+	require.Equal(t, first, names())
+	require.Len(t, sets.New(first...), len(first))
+}
+
+func TestNewGlobalEnvironment(t *testing.T) {
+	global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t),
+		fakeenv.WithStatus(widgets, fakeenv.Ready()),
+	)
+	ctx, env := global.Environment(environment.Managed(t))
+
+	ns, err := kubeclient.Get(ctx).CoreV1().Namespaces().Get(ctx, env.Namespace(), metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "reconciler-test", ns.Labels["app.kubernetes.io/name"])
+	require.Equal(t, environment.DefaultNamespaceTTL.String(), ns.Annotations[environment.NamespaceTTLAnnotation])
+	require.NotEmpty(t, ns.Annotations[environment.NamespaceCreatedAtAnnotation])
+
+	f := feature.NewFeature()
+	f.Setup("install widget", install("Widget", "w"))
+	f.Setup("install gadget", install("Gadget", "g"))
+	f.Requirement("widget is ready", k8s.IsReady(widgets, "w"))
+	f.Assert("gadget becomes ready", func(ctx context.Context, t feature.T) {
+		ft := featuretest.New("not ready").Run(ctx, k8s.IsReady(gadgets, "g", time.Millisecond, 10*time.Millisecond))
+		if !ft.Failed() {
+			t.Fatal("gadget is ready before its conditions are set")
+		}
+
+		if err := fakeenv.SetConditions(ctx, gadgets, env.Namespace(), "g", apis.Condition{
+			Type:   apis.ConditionReady,
+			Status: corev1.ConditionTrue,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		k8s.IsReady(gadgets, "g")(ctx, t)
+	})
+	f.Teardown("delete resources", f.DeleteResources)
+
+	env.Test(ctx, t, f)
+	require.Len(t, env.References(), 2)
+	env.Finish()
+
+	_, err = kubeclient.Get(ctx).CoreV1().Namespaces().Get(ctx, env.Namespace(), metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), err)
+	for name, gvr := range map[string]schema.GroupVersionResource{"w": widgets, "g": gadgets} {
+		_, err := dynamicclient.Get(ctx).Resource(gvr).Namespace(env.Namespace()).Get(ctx, name, metav1.GetOptions{})
+		require.True(t, apierrors.IsNotFound(err), err)
+	}
+}
+
+func TestWithListKinds(t *testing.T) {
+	global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t),
+		fakeenv.WithListKinds(map[schema.GroupVersionResource]string{widgets: "WidgetList"}),
+	)
+	ctx, env := global.Environment()
+	defer env.Finish()
+
+	ft := featuretest.New("install").Run(ctx, install("Widget", "w"))
+	ft.AssertPassed(t)
+
+	list, err := dynamicclient.Get(ctx).Resource(widgets).Namespace(env.Namespace()).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	require.Empty(t, list.Items[0].Object["status"])
+}
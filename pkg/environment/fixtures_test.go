@@ -0,0 +1,46 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment_test
+
+import (
+	"context"
+	"embed"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/manifest"
+)
+
+// fakeTemplates are the resources installed in the fakeenv environments of the
+// tests.
+//
+//go:embed testdata/fake/*.yaml
+var fakeTemplates embed.FS
+
+var widgets = schema.GroupVersionResource{Group: "example.dev", Version: "v1", Resource: "widgets"}
+
+func install(kind, name string) feature.StepFn {
+	return func(ctx context.Context, t feature.T) {
+		if _, err := manifest.InstallYamlFS(ctx, fakeTemplates, map[string]interface{}{
+			"kind": kind,
+			"name": name,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
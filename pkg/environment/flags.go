@@ -19,6 +19,7 @@ package environment
 import (
 	"flag"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -32,6 +33,8 @@ var (
 	l = new(feature.Levels)
 	f = new(string)
 
+	featureTags = new(string)
+
 	testNamespace = new(string)
 
 	ipFilePath     = new(string)
@@ -39,6 +42,37 @@ var (
 
 	pollTimeout  = new(time.Duration)
 	pollInterval = new(time.Duration)
+
+	stepTimeout = new(time.Duration)
+
+	dryRun = new(bool)
+
+	conformanceReport = new(string)
+
+	leakCheck = new(LeakCheckMode)
+
+	snapshotOnFail = new(bool)
+
+	upgradePhase = new(string)
+
+	seed = new(int64)
+
+	repeat           = new(int)
+	repeatUntilFail  = new(bool)
+	repeatNamespaces = new(bool)
+
+	shard        = new(Shard)
+	shardTimings = new(string)
+
+	maxParallelFeatures = new(int)
+
+	namespaceTTL = new(time.Duration)
+
+	namespacePoolSize  = new(int)
+	namespacePoolReuse = new(NamespacePoolReuse)
+
+	configFile    = new(string)
+	configProfile = new(string)
 )
 
 // InitFlags registers the requirement and state filter flags supported by the
@@ -64,12 +98,35 @@ func InitFlags(fs *flag.FlagSet) {
 
 	// Feature
 	fs.StringVar(f, "feature", "", "run only Features matching `regexp`")
+	fs.StringVar(featureTags, "feature.tags", "", "run only Features whose tags satisfy the boolean `expression`, for example \"tls && !slow\"")
+
+	fs.IntVar(repeat, "feature.repeat", 1, "Test each Feature `N` times, with a fresh state for each run unless the Feature sets its own, and report the pass rate to $ARTIFACTS/rekt-repeat/. The runs reuse the resource names chosen when the Feature was built, the resources of a run are deleted before the next one unless --feature.repeat.separate-namespaces is set")
+	fs.BoolVar(repeatUntilFail, "feature.repeat.until-fail", false, "Repeat each Feature until it fails, at most --feature.repeat times when above 1")
+	fs.BoolVar(repeatNamespaces, "feature.repeat.separate-namespaces", false, "Test each run of a repeated Feature in its own namespace")
+
+	fs.Var(shard, "rekt.shard", "Test only the Features of shard `i/n`, for example 2/4, assigned by a stable hash of their full name")
+	fs.StringVar(shardTimings, "rekt.shard.timings", "", "Assign the Features to shards so that shards take similar time, using the durations written to $ARTIFACTS/rekt-timings/ by a previous run, `path` is a file or a directory")
+
+	fs.IntVar(maxParallelFeatures, "rekt.max-parallel-features", 0, "Maximum number of Features tested at the same time by all the environments, whose steps then run in parallel within the -test.parallel slot of their Feature, 0 means no limit")
 
 	fs.StringVar(ipFilePath, "images.producer.file", "", "file path for file-based image producer")
 	fs.StringVar(testNamespace, "environment.namespace", "", "Test namespace")
 	fs.DurationVar(pollTimeout, "poll.timeout", state.DefaultPollTimeout, "Poll timeout")
 	fs.DurationVar(pollInterval, "poll.interval", state.DefaultPollInterval, "Poll interval")
+	fs.DurationVar(stepTimeout, "step.timeout", 0, "Default timeout of each step, 0 means no timeout")
 	fs.BoolVar(teardownOnFail, "teardown.on.fail", false, "Set this flag to do teardown even if test fails.")
+	fs.BoolVar(dryRun, "rekt.dry-run", false, "Write the plan of each feature to $ARTIFACTS instead of running it, no cluster is required.")
+	fs.Var(leakCheck, "rekt.leak-check", "Check for resources left in the test namespace, or cluster-scoped with the reconciler-test labels, that are not referenced by the environment at Finish, `mode` is disabled, warn or fail")
+	fs.BoolVar(snapshotOnFail, "rekt.snapshot-on-fail", true, "Write a snapshot of the test namespace to $ARTIFACTS/<feature>/ when a feature fails.")
+	fs.StringVar(upgradePhase, "rekt.upgrade.phase", string(UpgradeAllPhases), "Phases of the upgrade features to run: all around the upgrade, or pre and post the upgrade in separate invocations")
+	fs.Int64Var(seed, "rekt.seed", 0, "Seed of the random names generated by the framework, to reproduce the names of a previous run, 0 means a seed from the wall clock")
+	fs.StringVar(conformanceReport, "rekt.conformance-report", "", "Write a conformance report of the Assert steps to $ARTIFACTS/`name`.{json,md}")
+	fs.DurationVar(namespaceTTL, "rekt.namespace-ttl", DefaultNamespaceTTL, "How long the test namespaces are kept before the rekt-janitor command can delete them, stamped on the namespaces as the "+NamespaceTTLAnnotation+" annotation")
+	fs.IntVar(namespacePoolSize, "rekt.namespace-pool.size", 0, "Number of namespaces created ahead of the environments, in the background, 0 disables the namespace pool")
+	fs.Var(namespacePoolReuse, "rekt.namespace-pool.reuse", "What happens to the namespaces of the pool once the environment finishes, `policy` is never to delete them, or scrub to delete their resources and reuse them")
+
+	fs.StringVar(configFile, "rekt.config", os.Getenv(configFileEnv), "YAML file with the default values of the flags, flags set on the command line take precedence, defaults to $"+configFileEnv)
+	fs.StringVar(configProfile, "rekt.config.profile", "", "Profile of the --rekt.config file to apply, defaults to the profile set in the file")
 }
 
 type stateValue struct {
@@ -0,0 +1,99 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+
+	"knative.dev/reconciler-test/pkg/feature"
+)
+
+// StepHookFn is called around a step with the context and the T of the step,
+// it can register cleanups with t.Cleanup and fail the step with t.Error or
+// t.Fatal.
+type StepHookFn func(ctx context.Context, t feature.T, f *feature.Feature, s *feature.Step)
+
+// StepHooks are called around every step tested by an environment, nil hooks
+// are ignored.
+type StepHooks struct {
+	// BeforeStep is called before the step, which doesn't run when
+	// BeforeStep fails it.
+	BeforeStep StepHookFn
+	// AfterStep is called after the step, even when the step or a BeforeStep
+	// hook failed, with a context that is not done when the step timed out.
+	AfterStep StepHookFn
+	// OnFailure is called after the AfterStep hooks when the step failed,
+	// with the context of the AfterStep hooks.
+	OnFailure StepHookFn
+}
+
+// WithStepHooks is an environment option to register hooks called around every
+// step, in order, for example to capture the logs of controllers around each
+// assert, check for pod restarts or time the steps.
+func WithStepHooks(hooks ...StepHooks) EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		if e, ok := env.(*MagicEnvironment); ok {
+			e.stepHooks = append(e.stepHooks, hooks...)
+		}
+		return ctx, nil
+	}
+}
+
+// beforeStep calls the BeforeStep hooks until one fails the step, and returns
+// whether the step can run.
+func (mr *MagicEnvironment) beforeStep(ctx context.Context, t feature.T, f *feature.Feature, s *feature.Step) bool {
+	for _, h := range mr.stepHooks {
+		if h.BeforeStep == nil {
+			continue
+		}
+		callHook(ctx, t, f, s, "BeforeStep", h.BeforeStep)
+		if t.Failed() {
+			return false
+		}
+	}
+	return true
+}
+
+// afterStep calls the AfterStep hooks then, if the step failed, the OnFailure
+// hooks, even when an AfterStep hook stops the step with t.FailNow.
+func (mr *MagicEnvironment) afterStep(ctx context.Context, t feature.T, f *feature.Feature, s *feature.Step) {
+	defer func() {
+		if !t.Failed() {
+			return
+		}
+		for _, h := range mr.stepHooks {
+			if h.OnFailure != nil {
+				callHook(ctx, t, f, s, "OnFailure", h.OnFailure)
+			}
+		}
+	}()
+	for _, h := range mr.stepHooks {
+		if h.AfterStep != nil {
+			callHook(ctx, t, f, s, "AfterStep", h.AfterStep)
+		}
+	}
+}
+
+// callHook calls the hook, failing the step if the hook panics.
+func callHook(ctx context.Context, t feature.T, f *feature.Feature, s *feature.Step, name string, fn StepHookFn) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("Panic happened in %s hook: '%v'", name, r)
+		}
+	}()
+	fn(ctx, t, f, s)
+}
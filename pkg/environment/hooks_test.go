@@ -0,0 +1,123 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/milestone"
+)
+
+func TestStepHooks(t *testing.T) {
+	type hookKey struct{}
+
+	tests := map[string]struct {
+		// fail is the hook failing the step, if any.
+		fail   string
+		want   []string
+		failed bool
+	}{
+		"passing step": {
+			want: []string{"a before", "b before", "step", "a after", "b after", "a cleanup"},
+		},
+		"before fails": {
+			fail:   "b before",
+			want:   []string{"a before", "b before", "a after", "b after", "a failure", "b failure", "a cleanup"},
+			failed: true,
+		},
+		"after fails": {
+			fail:   "a after",
+			want:   []string{"a before", "b before", "step", "a after", "a failure", "b failure", "a cleanup"},
+			failed: true,
+		},
+		"hook panics": {
+			fail:   "a before panic",
+			want:   []string{"a before", "a after", "b after", "a failure", "b failure", "a cleanup"},
+			failed: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			var calls []string
+			record := func(call string) {
+				mu.Lock()
+				defer mu.Unlock()
+				calls = append(calls, call)
+			}
+			hook := func(call string) StepHookFn {
+				return func(ctx context.Context, t feature.T, f *feature.Feature, s *feature.Step) {
+					require.Equal(t, "hooks", ctx.Value(hookKey{}))
+					require.Equal(t, "Hooked", f.Name)
+					require.Equal(t, "step", s.Name)
+					record(call)
+					if call == "a before" {
+						t.Cleanup(func() { record("a cleanup") })
+					}
+					switch tc.fail {
+					case call:
+						t.Fatal("failing in ", call)
+					case call + " panic":
+						panic(call)
+					}
+				}
+			}
+			mr := &MagicEnvironment{
+				environmentSettings: environmentSettings{
+					l:          feature.All,
+					s:          feature.Any,
+					milestones: milestone.Compose(),
+				},
+			}
+			_, err := WithStepHooks(StepHooks{
+				BeforeStep: hook("a before"),
+				AfterStep:  hook("a after"),
+				OnFailure:  hook("a failure"),
+			}, StepHooks{
+				BeforeStep: hook("b before"),
+				AfterStep:  hook("b after"),
+				OnFailure:  hook("b failure"),
+			})(context.Background(), mr)
+			require.NoError(t, err)
+
+			// Prerequisite steps don't fail the test.
+			f := feature.NewFeatureNamed("Hooked")
+			f.Prerequisite("step", func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+				record("step")
+				return feature.PrerequisiteResult{ShouldRun: true}, nil
+			})
+
+			aggregator := newStepExecutionAggregator()
+			ctx := context.WithValue(context.Background(), hookKey{}, "hooks")
+			t.Run("Prerequisite", func(t *testing.T) {
+				mr.executeSteps(ctx, t, f, f.Steps, aggregator)
+			})
+
+			require.Equal(t, tc.want, calls)
+			if tc.failed {
+				require.Len(t, aggregator.Failed(), 1)
+			} else {
+				require.Empty(t, aggregator.Failed())
+			}
+		})
+	}
+}
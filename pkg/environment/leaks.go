@@ -0,0 +1,309 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+	"knative.dev/pkg/logging"
+
+	"knative.dev/reconciler-test/pkg/state"
+)
+
+const (
+	LeakedResourcesReason = "LeakedResources"
+
+	// TestLabelSelector selects the resources labeled like the test
+	// namespaces.
+	TestLabelSelector = "app.kubernetes.io/component=reconciler-test,app.kubernetes.io/name=reconciler-test"
+)
+
+// LeakCheckMode configures the check for resources that were left behind by
+// the features and not referenced by the environment, at Finish.
+type LeakCheckMode int
+
+const (
+	// LeakCheckDisabled doesn't check for leaked resources.
+	LeakCheckDisabled LeakCheckMode = iota
+	// LeakCheckWarn logs the leaked resources.
+	LeakCheckWarn
+	// LeakCheckFail fails the test when resources are leaked.
+	LeakCheckFail
+)
+
+var leakCheckModes = map[LeakCheckMode]string{
+	LeakCheckDisabled: "disabled",
+	LeakCheckWarn:     "warn",
+	LeakCheckFail:     "fail",
+}
+
+func (m LeakCheckMode) String() string {
+	if name, ok := leakCheckModes[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("LeakCheckMode(%d)", int(m))
+}
+
+// WithLeakCheck is an environment option to override the leak check mode set
+// by the rekt.leak-check flag.
+func WithLeakCheck(mode LeakCheckMode) EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		if e, ok := env.(*MagicEnvironment); ok {
+			e.leakCheck = mode
+		}
+		return ctx, nil
+	}
+}
+
+// Set implements flag.Value.
+func (m *LeakCheckMode) Set(s string) error {
+	for mode, name := range leakCheckModes {
+		if strings.EqualFold(s, name) {
+			*m = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown leak check mode %q, expected one of disabled, warn or fail", s)
+}
+
+// ignoredResources are never reported as leaked, events are recorded by the
+// system and the test namespace is deleted by the environment.
+var ignoredResources = sets.New(
+	schema.GroupResource{Resource: "events"},
+	schema.GroupResource{Group: "events.k8s.io", Resource: "events"},
+	schema.GroupResource{Resource: "namespaces"},
+)
+
+type leakKey struct {
+	group     string
+	kind      string
+	namespace string
+	name      string
+}
+
+func leakKeyOf(ref corev1.ObjectReference) leakKey {
+	gv, _ := schema.ParseGroupVersion(ref.APIVersion)
+	return leakKey{group: gv.Group, kind: ref.Kind, namespace: ref.Namespace, name: ref.Name}
+}
+
+// checkLeaks reports, according to the leak check mode, the resources of the
+// test namespace and the cluster-scoped resources with the reconciler-test
+// labels referencing the test namespace that are not referenced by the
+// environment. Resources with owners are left to the garbage collector and
+// not reported.
+func (mr *MagicEnvironment) checkLeaks() {
+	if mr.leakCheck == LeakCheckDisabled || mr.dryRun {
+		return
+	}
+
+	leaks, err := mr.findLeaks(mr.c)
+	if err != nil {
+		mr.reportLeaks(fmt.Sprintf("failed to check for leaked resources: %v", err))
+		return
+	}
+	if len(leaks) == 0 {
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d resource(s) not referenced by the environment:", len(leaks))
+	for _, ref := range leaks {
+		name := ref.Name
+		if ref.Namespace != "" {
+			name = ref.Namespace + "/" + name
+		}
+		fmt.Fprintf(&sb, "\n  %s %s %s", ref.APIVersion, ref.Kind, name)
+	}
+	mr.reportLeaks(sb.String())
+}
+
+func (mr *MagicEnvironment) reportLeaks(msg string) {
+	if mr.leakCheck == LeakCheckWarn {
+		logging.FromContext(mr.c).Warn(msg)
+		return
+	}
+	if mr.milestones != nil {
+		mr.milestones.Exception(LeakedResourcesReason, "%s", msg)
+	}
+	if mr.managedT != nil {
+		mr.managedT.Error(msg)
+	} else {
+		logging.FromContext(mr.c).Error(msg)
+	}
+}
+
+func (mr *MagicEnvironment) findLeaks(ctx context.Context) ([]corev1.ObjectReference, error) {
+	resources, err := listableResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := sets.New[leakKey]()
+	for _, ref := range mr.References() {
+		referenced.Insert(leakKeyOf(ref))
+	}
+	ignored := sets.New(
+		leakKey{kind: "ServiceAccount", namespace: mr.namespace, name: "default"},
+		leakKey{kind: "ConfigMap", namespace: mr.namespace, name: "kube-root-ca.crt"},
+		leakKey{kind: "Secret", namespace: mr.namespace, name: mr.imagePullSecretName},
+	)
+	for k := range referenced {
+		// Endpoints are maintained by the system for each Service.
+		if k.group == "" && k.kind == "Service" {
+			ignored.Insert(leakKey{kind: "Endpoints", namespace: k.namespace, name: k.name})
+		}
+	}
+
+	dc := dynamicclient.Get(ctx)
+	var leaks []corev1.ObjectReference
+	for _, r := range resources {
+		if ignoredResources.Has(r.GroupResource()) {
+			continue
+		}
+
+		var l *unstructured.UnstructuredList
+		if r.namespaced {
+			l, err = dc.Resource(r.GroupVersionResource).Namespace(mr.namespace).List(ctx, metav1.ListOptions{})
+		} else {
+			l, err = dc.Resource(r.GroupVersionResource).List(ctx, metav1.ListOptions{LabelSelector: TestLabelSelector})
+		}
+		if err != nil {
+			if ignoreListError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %s: %w", r.GroupVersionResource, err)
+		}
+
+		for _, obj := range l.Items {
+			// Cluster-scoped resources that existed before the environment or
+			// that don't reference its namespace belong to other
+			// environments, which may run concurrently.
+			if created := obj.GetCreationTimestamp(); !r.namespaced && !created.IsZero() && created.Time.Before(mr.createdAt) {
+				continue
+			}
+			if !r.namespaced && !referencesValue(obj.Object, mr.namespace) {
+				continue
+			}
+			ref := corev1.ObjectReference{
+				APIVersion: r.GroupVersion().String(),
+				Kind:       r.kind,
+				Namespace:  obj.GetNamespace(),
+				Name:       obj.GetName(),
+			}
+			key := leakKeyOf(ref)
+			// State stores outlive the test on purpose.
+			if _, isState := obj.GetLabels()[state.ConfigMapLabel]; isState {
+				continue
+			}
+			if len(obj.GetOwnerReferences()) > 0 || referenced.Has(key) || ignored.Has(key) {
+				continue
+			}
+			leaks = append(leaks, ref)
+		}
+	}
+	sort.Slice(leaks, func(i, j int) bool {
+		a, b := leaks[i], leaks[j]
+		if a.APIVersion != b.APIVersion {
+			return a.APIVersion < b.APIVersion
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return leaks, nil
+}
+
+// referencesValue reports whether v, the content of an object, contains the
+// string value s, such as a namespace in a name, a label, a subject or a
+// service reference.
+func referencesValue(v interface{}, s string) bool {
+	switch v := v.(type) {
+	case string:
+		return strings.Contains(v, s)
+	case map[string]interface{}:
+		for _, e := range v {
+			if referencesValue(e, s) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			if referencesValue(e, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// discoveredResource is a resource type that can be listed.
+type discoveredResource struct {
+	schema.GroupVersionResource
+	kind       string
+	namespaced bool
+}
+
+// listableResources returns the preferred version of every resource type of
+// the API server that supports list, ignoring the API groups that failed
+// discovery.
+func listableResources(ctx context.Context) ([]discoveredResource, error) {
+	lists, err := discovery.ServerPreferredResources(kubeclient.Get(ctx).Discovery())
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	var resources []discoveredResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") || !sets.New(r.Verbs...).Has("list") {
+				continue
+			}
+			resources = append(resources, discoveredResource{
+				GroupVersionResource: gv.WithResource(r.Name),
+				kind:                 r.Kind,
+				namespaced:           r.Namespaced,
+			})
+		}
+	}
+	return resources, nil
+}
+
+// ignoreListError reports whether listing a resource type failed because it
+// can't be listed by the test, rather than because of the API server.
+func ignoreListError(err error) bool {
+	return apierrors.IsNotFound(err) || apierrors.IsForbidden(err) || apierrors.IsMethodNotSupported(err)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/environment/fakeenv"
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/feature/featuretest"
+	testlog "knative.dev/reconciler-test/pkg/logging"
+)
+
+// gizmos are cluster-scoped.
+var gizmos = schema.GroupVersionResource{Group: "example.dev", Version: "v1", Resource: "gizmos"}
+
+func create(ctx context.Context, t feature.T, gvr schema.GroupVersionResource, namespace string, obj map[string]interface{}) {
+	u := &unstructured.Unstructured{Object: obj}
+	if _, err := dynamicclient.Get(ctx).Resource(gvr).Namespace(namespace).Create(ctx, u, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func leakyFeature(ctx context.Context, t feature.T, global environment.GlobalEnvironment, mode environment.LeakCheckMode) {
+	ctx, env := global.Environment(environment.Managed(t), environment.WithLeakCheck(mode))
+	kubeclient.Get(ctx).Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "example.dev/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "widgets", Namespaced: true, Kind: "Widget", Verbs: []string{"create", "list", "delete"}},
+			{Name: "widgets/status", Namespaced: true, Kind: "Widget", Verbs: []string{"update"}},
+			{Name: "gizmos", Kind: "Gizmo", Verbs: []string{"create", "list", "delete"}},
+		},
+	}}
+
+	ns := env.Namespace()
+	install("Widget", "referenced")(ctx, t)
+	create(ctx, t, widgets, ns, map[string]interface{}{
+		"apiVersion": "example.dev/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "leaked", "namespace": ns},
+	})
+	create(ctx, t, widgets, ns, map[string]interface{}{
+		"apiVersion": "example.dev/v1",
+		"kind":       "Widget",
+		"metadata": map[string]interface{}{"name": "owned", "namespace": ns, "ownerReferences": []interface{}{
+			map[string]interface{}{"apiVersion": "example.dev/v1", "kind": "Widget", "name": "referenced", "uid": "1234"},
+		}},
+	})
+	labels := map[string]interface{}{
+		"app.kubernetes.io/component": "reconciler-test",
+		"app.kubernetes.io/name":      "reconciler-test",
+	}
+	create(ctx, t, gizmos, "", map[string]interface{}{
+		"apiVersion": "example.dev/v1",
+		"kind":       "Gizmo",
+		"metadata":   map[string]interface{}{"name": "leaked", "labels": labels},
+		"spec":       map[string]interface{}{"subjects": []interface{}{map[string]interface{}{"namespace": ns}}},
+	})
+	// Another environment created it.
+	create(ctx, t, gizmos, "", map[string]interface{}{
+		"apiVersion": "example.dev/v1",
+		"kind":       "Gizmo",
+		"metadata":   map[string]interface{}{"name": "concurrent", "labels": labels},
+		"spec":       map[string]interface{}{"subjects": []interface{}{map[string]interface{}{"namespace": "test-other"}}},
+	})
+	create(ctx, t, gizmos, "", map[string]interface{}{
+		"apiVersion": "example.dev/v1",
+		"kind":       "Gizmo",
+		"metadata":   map[string]interface{}{"name": "unlabeled"},
+	})
+}
+
+func TestLeakCheck(t *testing.T) {
+	tests := map[string]struct {
+		mode   environment.LeakCheckMode
+		failed bool
+	}{
+		"disabled": {mode: environment.LeakCheckDisabled},
+		"warn":     {mode: environment.LeakCheckWarn},
+		"fail":     {mode: environment.LeakCheckFail, failed: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t),
+				fakeenv.WithListKinds(map[schema.GroupVersionResource]string{
+					widgets: "WidgetList",
+					gizmos:  "GizmoList",
+				}),
+			)
+			ft := featuretest.New(name).Run(context.Background(), func(ctx context.Context, ft feature.T) {
+				leakyFeature(ctx, ft, global, tc.mode)
+			})
+			if !tc.failed {
+				ft.AssertPassed(t)
+				return
+			}
+			ft.AssertFailedWith(t, "2 resource(s) not referenced by the environment")
+			errs := strings.Join(ft.Errors(), "\n")
+			require.Regexp(t, `example.dev/v1 Gizmo leaked\n  example.dev/v1 Widget test-[a-z]+/leaked`, errs)
+			require.NotContains(t, errs, "/referenced")
+			require.NotContains(t, errs, "owned")
+			require.NotContains(t, errs, "unlabeled")
+			require.NotContains(t, errs, "concurrent")
+		})
+	}
+}
+
+func TestLeakCheckModeSet(t *testing.T) {
+	var mode environment.LeakCheckMode
+	require.NoError(t, mode.Set("fail"))
+	require.Equal(t, environment.LeakCheckFail, mode)
+	require.Equal(t, "fail", mode.String())
+	require.Error(t, mode.Set("panic"))
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+
+	"knative.dev/reconciler-test/pkg/feature"
+)
+
+// LocalConfig configures a GlobalEnvironment created with
+// NewLocalGlobalEnvironment.
+type LocalConfig struct {
+	// PollInterval and PollTimeout are the default poll timings of the
+	// environments.
+	PollInterval time.Duration
+	PollTimeout  time.Duration
+
+	// Seed seeds the random names of the environments, as the rekt.seed flag
+	// does, without replacing the feature.DefaultRand. Zero means unseeded.
+	Seed int64
+
+	// NamespacePoolSize and NamespacePoolReuse create the namespaces of the
+	// environments ahead of them, as the rekt.namespace-pool flags do.
+	NamespacePoolSize  int
+	NamespacePoolReuse NamespacePoolReuse
+
+	// CreateDefaultServiceAccount creates the default ServiceAccount of the
+	// test namespaces, for clusters where no controller manager does it.
+	CreateDefaultServiceAccount bool
+}
+
+// NewLocalGlobalEnvironment creates a GlobalEnvironment which ignores the
+// command line flags, using the clients already registered in the context,
+// for example the fake clients of the fakeenv package. Images are not built,
+// the image producer returns the package names as they are.
+func NewLocalGlobalEnvironment(ctx context.Context, cfg LocalConfig) GlobalEnvironment {
+	if cfg.CreateDefaultServiceAccount {
+		ctx = withDefaultServiceAccount(ctx)
+	}
+	ctx = withImageProducer(ctx, noopImageProducer)
+
+	var r *feature.Rand
+	if cfg.Seed != 0 {
+		r = feature.NewRand(cfg.Seed)
+	}
+
+	return &MagicGlobalEnvironment{
+		RequirementLevel: feature.All,
+		FeatureState:     feature.Any,
+		FeatureMatch:     regexp.MustCompile(""),
+		c:                initializeImageStores(ctx),
+		instanceID:       uuid.New().String(),
+		pollInterval:     cfg.PollInterval,
+		pollTimeout:      cfg.PollTimeout,
+		rand:             r,
+		namespacePool: newNamespacePool(ContextWithPollTimings(ctx, cfg.PollInterval, cfg.PollTimeout),
+			cfg.NamespacePoolSize, cfg.NamespacePoolReuse, r),
+	}
+}
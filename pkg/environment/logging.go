@@ -25,18 +25,29 @@ import (
 	testlog "knative.dev/reconciler-test/pkg/logging"
 )
 
-// loggingSteps returns a number of steps that logs environment-managed resources.
+// loggingSteps returns a number of steps that logs environment-managed
+// resources and, if enabled, writes a snapshot of the test namespace.
 func (mr *MagicEnvironment) loggingSteps() []feature.Step {
 	mr.refsMu.Lock()
 	defer mr.refsMu.Unlock()
 
-	return []feature.Step{{
+	steps := []feature.Step{{
 		Name: "Log references",
 		S:    feature.Any,
 		L:    feature.Must,
 		T:    feature.Teardown,
 		Fn:   feature.LogReferences(mr.refs...),
 	}}
+	if mr.snapshotOnFail {
+		steps = append(steps, feature.Step{
+			Name: "Snapshot namespace",
+			S:    feature.Any,
+			L:    feature.Must,
+			T:    feature.Teardown,
+			Fn:   SnapshotNamespace,
+		})
+	}
+	return steps
 }
 
 // WithTestLogger returns a context with test logger configured.
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -40,21 +41,65 @@ import (
 // context.Context, and optional initializers slice. The provided context is
 // expected to contain the configured Kube client already.
 func NewGlobalEnvironment(ctx context.Context, initializers ...func()) GlobalEnvironment {
+	ctx = withNamespaceTTL(ctx, *namespaceTTL)
+	r := newSeededRand(ctx, *seed)
+	id := uuid.New().String()
 	return &MagicGlobalEnvironment{
 		RequirementLevel: *l,
 		FeatureState:     *s,
 		FeatureMatch:     regexp.MustCompile(*f),
+		FeatureTags:      feature.MustParseTagExpression(*featureTags),
+		UpgradePhase:     UpgradePhase(*upgradePhase),
 		c:                initializeImageStores(ctx),
-		instanceID:       uuid.New().String(),
+		instanceID:       id,
 		initializers:     initializers,
 		teardownOnFail:   *teardownOnFail,
+		stepTimeout:      *stepTimeout,
+		dryRun:           *dryRun,
+		conformance:      newConformanceReporter(ctx, *conformanceReport),
+		leakCheck:        *leakCheck,
+		snapshotOnFail:   *snapshotOnFail,
+		rand:             r,
+		repeat:           *repeat,
+		repeatUntilFail:  *repeatUntilFail,
+		repeatNamespaces: *repeatNamespaces,
+		shard:            loadShard(ctx, *shard, *shardTimings),
+		timings:          newFeatureTimings(id, *shard, *shardTimings),
+		featureSlots:     newFeatureSlots(*maxParallelFeatures),
+		namespacePool:    newGlobalNamespacePool(ctx, r),
 	}
 }
 
+// newGlobalNamespacePool creates the namespace pool configured by the
+// rekt.namespace-pool flags, if any.
+func newGlobalNamespacePool(ctx context.Context, r *feature.Rand) *namespacePool {
+	if *dryRun {
+		return nil
+	}
+	ctx = ContextWithPollTimings(ctx, *pollInterval, *pollTimeout)
+	return newNamespacePool(ctx, *namespacePoolSize, *namespacePoolReuse, r)
+}
+
+// newSeededRand creates the Rand of the global environment, seeded from the
+// wall clock when seed is 0, and makes it the default one so that the names
+// generated while building the features are reproducible too.
+func newSeededRand(ctx context.Context, seed int64) *feature.Rand {
+	if seed == 0 {
+		seed = time.Now().UTC().UnixNano()
+	}
+	r := feature.NewRand(seed)
+	feature.SetDefaultRand(r)
+	logging.FromContext(ctx).Infof("Random names seed %d, rerun with --rekt.seed=%d to reproduce them", seed, seed)
+	return r
+}
+
 type MagicGlobalEnvironment struct {
 	RequirementLevel feature.Levels
 	FeatureState     feature.States
 	FeatureMatch     *regexp.Regexp
+	FeatureTags      *feature.TagExpression
+	// UpgradePhase selects the phases run by TestUpgrade.
+	UpgradePhase UpgradePhase
 
 	c context.Context
 	// instanceID represents this instance of the GlobalEnvironment. It is used
@@ -63,30 +108,127 @@ type MagicGlobalEnvironment struct {
 	initializers     []func()
 	initializersOnce sync.Once
 	teardownOnFail   bool
+	stepTimeout      time.Duration
+	dryRun           bool
+	// pollInterval and pollTimeout override the poll timings flags when set.
+	pollInterval time.Duration
+	pollTimeout  time.Duration
+	// conformance is shared by all the environments to build a single
+	// conformance report, if configured.
+	conformance    *milestone.ConformanceReporter
+	leakCheck      LeakCheckMode
+	snapshotOnFail bool
+	// rand is the Rand from which the Rand of each environment is derived,
+	// the DefaultRand when not set.
+	rand *feature.Rand
+
+	repeat           int
+	repeatUntilFail  bool
+	repeatNamespaces bool
+
+	shard Shard
+	// timings records the durations of the features, if configured.
+	timings *featureTimings
+	// featureSlots limits the number of features tested at the same time by
+	// all the environments, if configured.
+	featureSlots featureSlots
+	// namespacePool hands out namespaces created ahead of the environments,
+	// if configured.
+	namespacePool *namespacePool
+}
+
+func newConformanceReporter(ctx context.Context, name string) *milestone.ConformanceReporter {
+	if name == "" {
+		return nil
+	}
+	return milestone.NewConformanceReporter(ctx, name)
 }
 
 type MagicEnvironment struct {
-	c            context.Context
-	l            feature.Levels
-	s            feature.States
-	featureMatch *regexp.Regexp
+	environmentSettings
+
+	c context.Context
 
 	namespace        string
 	namespaceCreated bool
 	refs             []corev1.ObjectReference
 	refsMu           sync.Mutex
 
-	// milestones sends milestone events, if configured.
-	milestones milestone.Emitter
-
 	// managedT is used for test-scoped logging, if configured.
 	managedT feature.T
 
+	repeatReports   []*repeatReport
+	repeatReportsMu sync.Mutex
+
+	// namespacePool is the pool the namespace was handed out by, if any.
+	namespacePool *namespacePool
+}
+
+// environmentSettings are the settings of a MagicEnvironment, shared with the
+// environments of the runs of a repeated feature.
+type environmentSettings struct {
+	l            feature.Levels
+	s            feature.States
+	featureMatch *regexp.Regexp
+	featureTags  *feature.TagExpression
+
+	// milestones sends milestone events, if configured.
+	milestones milestone.Emitter
+
 	// imagePullSecretNamespace/imagePullSecretName: An optional secret to add to service account of new namespaces
 	imagePullSecretName      string
 	imagePullSecretNamespace string
 
 	teardownOnFail bool
+
+	// stepTimeout is the timeout of steps that don't set their own.
+	stepTimeout time.Duration
+
+	// dryRun writes the features plan instead of testing them.
+	dryRun bool
+
+	// leakCheck reports the resources that are not referenced at Finish.
+	leakCheck LeakCheckMode
+	// createdAt is the creation time of the environment, truncated to the
+	// precision of the creation timestamps of the resources.
+	createdAt time.Time
+
+	// seed is the seed of the global environment, rand generates the random
+	// names of the environment.
+	seed int64
+	rand *feature.Rand
+
+	// snapshotOnFail writes a snapshot of the test namespace when a feature
+	// fails.
+	snapshotOnFail bool
+
+	// stateStore creates the state.Store of the features that don't have one.
+	stateStore StateStoreFunc
+
+	// repeat is the number of times each feature is tested, until it fails
+	// with repeatUntilFail, in a namespace for each run with
+	// repeatNamespaces.
+	repeat           int
+	repeatUntilFail  bool
+	repeatNamespaces bool
+
+	// shard selects the features tested, timings records their durations.
+	shard   Shard
+	timings *featureTimings
+
+	// featureSlots is shared by all the environments of the global
+	// environment.
+	featureSlots featureSlots
+
+	// stepHooks are called around every step.
+	stepHooks []StepHooks
+
+	// namespaceQuota, limitRange and networkIsolation, allowing ingress from
+	// allowFrom, are created with the namespace, if configured.
+	namespaceQuota   corev1.ResourceList
+	limitRange       []corev1.LimitRangeItem
+	networkIsolation bool
+	allowFrom        []string
 }
 
 var (
@@ -108,6 +250,30 @@ func isParallel(ctx context.Context) bool {
 	return v != nil && v.(bool)
 }
 
+type groupKey struct{}
+
+// withinGroup marks the features tested as groups of another feature.
+func withinGroup(ctx context.Context) context.Context {
+	return context.WithValue(ctx, groupKey{}, true)
+}
+
+func isGroup(ctx context.Context) bool {
+	v := ctx.Value(groupKey{})
+	return v != nil && v.(bool)
+}
+
+type groupTagsKey struct{}
+
+// withGroupTags sets the tags inherited by the groups of a feature.
+func withGroupTags(ctx context.Context, tags []string) context.Context {
+	return context.WithValue(ctx, groupTagsKey{}, tags)
+}
+
+func groupTags(ctx context.Context) []string {
+	tags, _ := ctx.Value(groupTagsKey{}).([]string)
+	return tags
+}
+
 func (mr *MagicEnvironment) Reference(ref ...corev1.ObjectReference) {
 	mr.refsMu.Lock()
 	defer mr.refsMu.Unlock()
@@ -128,9 +294,17 @@ func (mr *MagicEnvironment) Finish() {
 	// Delete the namespace after sending the Finished milestone event
 	// since emitters might use the namespace.
 	var result milestone.Result = unknownResult{}
+	// Check for leaks before the referenced resources are deleted, they are
+	// still kept when only leaks were found.
+	failed := mr.managedT != nil && mr.managedT.Failed()
+	mr.logRepeatReports()
+	if err := mr.timings.write(); err != nil {
+		logging.FromContext(mr.c).Warn("Failed to write the durations of the features: ", err)
+	}
+	mr.checkLeaks()
 	if mr.managedT != nil {
 		result = mr.managedT
-		if !result.Failed() {
+		if !failed {
 			if err := feature.DeleteResources(mr.c, mr.managedT, mr.References()); err != nil {
 				mr.managedT.Fatal(err)
 			}
@@ -155,6 +329,17 @@ func WithPollTimings(interval, timeout time.Duration) EnvOpts {
 	}
 }
 
+// WithStepTimeout is an environment option to override the default timeout of
+// steps that don't set their own with feature.Timeout.
+func WithStepTimeout(timeout time.Duration) EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		if e, ok := env.(*MagicEnvironment); ok {
+			e.stepTimeout = timeout
+		}
+		return ctx, nil
+	}
+}
+
 // Managed enables auto-lifecycle management of the environment. Including
 // registration of following opts:
 //   - Cleanup,
@@ -185,39 +370,87 @@ func WithEmitter(emitter milestone.Emitter) EnvOpts {
 }
 
 func (mr *MagicGlobalEnvironment) Environment(opts ...EnvOpts) (context.Context, Environment) {
-	opts = append(opts, inNamespace())
+	r := mr.rand
+	if r == nil {
+		r = feature.DefaultRand()
+	}
 
 	env := &MagicEnvironment{
-		c:              mr.c,
-		l:              mr.RequirementLevel,
-		s:              mr.FeatureState,
-		featureMatch:   mr.FeatureMatch,
-		teardownOnFail: mr.teardownOnFail,
-
-		imagePullSecretName:      "kn-test-image-pull-secret",
-		imagePullSecretNamespace: "default",
+		environmentSettings: environmentSettings{
+			l:                        mr.RequirementLevel,
+			s:                        mr.FeatureState,
+			featureMatch:             mr.FeatureMatch,
+			featureTags:              mr.FeatureTags,
+			teardownOnFail:           mr.teardownOnFail,
+			stepTimeout:              mr.stepTimeout,
+			dryRun:                   mr.dryRun,
+			leakCheck:                mr.leakCheck,
+			createdAt:                time.Now().Truncate(time.Second),
+			snapshotOnFail:           mr.snapshotOnFail,
+			stateStore:               newKVStore,
+			repeat:                   mr.repeat,
+			repeatUntilFail:          mr.repeatUntilFail,
+			repeatNamespaces:         mr.repeatNamespaces,
+			shard:                    mr.shard,
+			timings:                  mr.timings,
+			featureSlots:             mr.featureSlots,
+			seed:                     r.Seed(),
+			imagePullSecretName:      defaultImagePullSecretName,
+			imagePullSecretNamespace: defaultImagePullSecretNamespace,
+		},
+		c: mr.c,
 	}
 
 	ctx := ContextWith(mr.c, env)
-	ctx = ContextWithPollTimings(ctx, *pollInterval, *pollTimeout)
+	interval, timeout := mr.pollInterval, mr.pollTimeout
+	if interval == 0 || timeout == 0 {
+		interval, timeout = *pollInterval, *pollTimeout
+	}
+	ctx = ContextWithPollTimings(ctx, interval, timeout)
+
+	// The options might need the cluster, which isn't available in dry-run
+	// mode.
+	if !env.dryRun {
+		for _, opt := range opts {
+			if nctx, err := opt(ctx, env); err != nil {
+				logging.FromContext(ctx).Fatal(err)
+			} else {
+				ctx = nctx
+			}
+		}
+	}
 
-	for _, opt := range opts {
-		if nctx, err := opt(ctx, env); err != nil {
+	// The Rand of a managed environment is derived from the name of its test,
+	// so that the names don't depend on the order in which parallel tests
+	// create their environments.
+	key := ""
+	if env.managedT != nil {
+		key = env.managedT.Name()
+	}
+	env.rand = r.DeriveFor(key)
+	ctx = feature.ContextWithRand(ctx, env.rand)
+	if !env.dryRun {
+		var err error
+		if ctx, err = inNamespace()(ctx, env); err != nil {
 			logging.FromContext(ctx).Fatal(err)
-		} else {
-			ctx = nctx
 		}
 	}
 	env.c = ctx
 
+	if err := mr.namespaceFromPool(ctx, env); err != nil {
+		logging.FromContext(ctx).Fatal(err)
+	}
+
 	log := logging.FromContext(ctx)
-	log.Infof("Environment settings: level %s, state %s, feature %q",
-		env.l, env.s, env.featureMatch)
-	mr.initializersOnce.Do(func() {
-		for _, initializer := range mr.initializers {
-			initializer()
-		}
-	})
+	log.Infof("Environment settings: level %s, state %s, feature %q, tags %q, dry-run %t, seed %d",
+		env.l, env.s, env.featureMatch, env.featureTags, env.dryRun, env.seed)
+	if !env.dryRun {
+		mr.initializersOnce.Do(func() {
+			for _, initializer := range mr.initializers {
+				initializer()
+			}
+		})
+	}
 
 	eventEmitter, err := milestone.NewMilestoneEmitterFromEnv(mr.instanceID, env.namespace)
 	if err != nil {
@@ -226,29 +459,44 @@ func (mr *MagicGlobalEnvironment) Environment(opts ...EnvOpts) (context.Context,
 	}
 	logEmitter := milestone.NewLogEmitter(ctx, env.namespace)
 
+	emitters := []milestone.Emitter{eventEmitter, logEmitter}
+	if mr.conformance != nil {
+		emitters = append(emitters, mr.conformance)
+	}
 	if env.milestones == nil {
-		env.milestones = milestone.Compose(eventEmitter, logEmitter)
+		env.milestones = milestone.Compose(emitters...)
 	} else {
 		// Compose the emitters with those passed through opts.
-		env.milestones = milestone.Compose(env.milestones, eventEmitter, logEmitter)
-	}
-
-	if err := env.CreateNamespaceIfNeeded(); err != nil {
-		logging.FromContext(ctx).Fatal(err)
+		env.milestones = milestone.Compose(append([]milestone.Emitter{env.milestones}, emitters...)...)
 	}
 
-	for _, in := range GetPostInit(ctx) {
-		ctx, err = in(ctx, env)
-		if err != nil {
+	if !env.dryRun {
+		if env.namespacePool != nil {
+			// The namespace was created by the pool.
+			env.milestones.NamespaceCreated(env.namespace)
+		} else if err := env.CreateNamespaceIfNeeded(); err != nil {
 			logging.FromContext(ctx).Fatal(err)
 		}
+
+		for _, in := range GetPostInit(ctx) {
+			ctx, err = in(ctx, env)
+			if err != nil {
+				logging.FromContext(ctx).Fatal(err)
+			}
+		}
 	}
 
 	env.milestones.Environment(map[string]string{
 		// TODO: we could add more detail here, don't send secrets.
-		"requirementLevel": env.RequirementLevel().String(),
-		"featureState":     env.FeatureState().String(),
-		"namespace":        env.Namespace(),
+		"requirementLevel":    env.RequirementLevel().String(),
+		"featureState":        env.FeatureState().String(),
+		"namespace":           env.Namespace(),
+		"featureTags":         env.featureTags.String(),
+		"dryRun":              strconv.FormatBool(env.dryRun),
+		"leakCheck":           env.leakCheck.String(),
+		"seed":                strconv.FormatInt(env.seed, 10),
+		"shard":               env.shard.String(),
+		"maxParallelFeatures": strconv.Itoa(cap(env.featureSlots)),
 	})
 
 	return ctx, env
@@ -276,7 +524,7 @@ func inNamespace() EnvOpts {
 	return func(ctx context.Context, env Environment) (context.Context, error) {
 		ns := getNamespace(ctx)
 		if ns == "" {
-			ns = feature.MakeK8sNamePrefix(feature.AppendRandomString("test"))
+			ns = feature.RandFromContext(ctx).MakeRandomK8sName("test")
 		}
 		return InNamespace(ns)(ctx, env)
 	}
@@ -349,13 +597,60 @@ func (mr *MagicEnvironment) Prerequisite(ctx context.Context, t *testing.T, f *f
 // Test will create a new store.KVStore and set it on the feature and then
 // apply it to the Context.
 func (mr *MagicEnvironment) Test(ctx context.Context, originalT *testing.T, f *feature.Feature) {
-	mr.test(ctx, originalT, f)
+	mr.runFeature(ctx, originalT, f)
 }
 
 // ParallelTest implements Environment.ParallelTest.
 // It is similar to Test with the addition of running the feature in parallel
 func (mr *MagicEnvironment) ParallelTest(ctx context.Context, originalT *testing.T, f *feature.Feature) {
-	mr.test(withParallel(ctx), originalT, f)
+	mr.runFeature(withParallel(ctx), originalT, f)
+}
+
+// runFeature tests the feature unless it is filtered out by the environment
+// selectors, in which case it is reported as skipped.
+func (mr *MagicEnvironment) runFeature(ctx context.Context, originalT *testing.T, f *feature.Feature) {
+	originalT.Helper() // Helper marks the calling function as a test helper function.
+
+	// The phases of an upgrade are selected as a whole by TestUpgrade.
+	upgradePhase := isUpgradePhase(ctx)
+
+	if !upgradePhase && !mr.featureTags.Matches(f.GetTags()) {
+		reason := fmt.Sprintf("feature tags %q don't satisfy --feature.tags=%q", f.GetTags(), mr.featureTags.String())
+		mr.skipFeature(originalT, f, reason)
+		return
+	}
+
+	if !upgradePhase && !mr.shard.Includes(f.Name) {
+		reason := fmt.Sprintf("feature is in shard %d/%d, not in --rekt.shard=%s", mr.shard.Of(f.Name), mr.shard.Count, mr.shard.String())
+		mr.skipFeature(originalT, f, reason)
+		return
+	}
+
+	if mr.dryRun {
+		if !upgradePhase && !mr.featureMatch.MatchString(f.Name) {
+			mr.skipFeature(originalT, f, fmt.Sprintf("--feature=%s doesn't match", mr.featureMatch.String()))
+			return
+		}
+		mr.writePlan(originalT, f)
+		return
+	}
+
+	if mr.repeated() {
+		mr.repeatFeature(ctx, originalT, f)
+		return
+	}
+
+	mr.test(ctx, originalT, f)
+}
+
+// skipFeature reports the feature as skipped with the given reason.
+func (mr *MagicEnvironment) skipFeature(originalT *testing.T, f *feature.Feature, reason string) {
+	originalT.Helper()
+
+	milestone.EmitTestSkipped(mr.milestones, f.Name, reason, originalT)
+	originalT.Run(f.Name, func(t *testing.T) {
+		t.Skip(reason)
+	})
 }
 
 // Test implements Environment.Test.
@@ -366,9 +661,14 @@ func (mr *MagicEnvironment) ParallelTest(ctx context.Context, originalT *testing
 func (mr *MagicEnvironment) test(ctx context.Context, originalT *testing.T, f *feature.Feature) {
 	originalT.Helper() // Helper marks the calling function as a test helper function.
 
+	// A group has the tags of the features it is part of.
+	tags := append(append([]string(nil), groupTags(ctx)...), f.GetTags()...)
 	for i, g := range f.GetGroups() {
 		originalT.Run(fmt.Sprintf("group-%d", i+1), func(t *testing.T) {
-			mr.test(ctx, t, g)
+			if gt := append(tags[:len(tags):len(tags)], g.GetTags()...); !mr.featureTags.Matches(gt) {
+				t.Skipf("group tags %q don't satisfy --feature.tags=%q", gt, mr.featureTags.String())
+			}
+			mr.test(withGroupTags(withinGroup(ctx), tags), t, g)
 		})
 		if originalT.Failed() { // If a group fails, return
 			return
@@ -379,8 +679,9 @@ func (mr *MagicEnvironment) test(ctx context.Context, originalT *testing.T, f *f
 	f.DumpWith(log.Debug)
 	defer f.DumpWith(log.Debug) // Log feature state at the end of the run
 
-	if !mr.featureMatch.MatchString(f.Name) {
+	if !isUpgradePhase(ctx) && !mr.featureMatch.MatchString(f.Name) {
 		log.Warnf("Skipping feature '%s' assertions because --feature=%s  doesn't match", f.Name, mr.featureMatch.String())
+		milestone.EmitTestSkipped(mr.milestones, f.Name, fmt.Sprintf("--feature=%s doesn't match", mr.featureMatch.String()), originalT)
 		return
 	}
 
@@ -388,10 +689,19 @@ func (mr *MagicEnvironment) test(ctx context.Context, originalT *testing.T, f *f
 	defer mr.milestones.TestFinished(f.Name, originalT)
 
 	if f.State == nil {
-		f.State = &state.KVStore{}
+		s, err := mr.stateStore(ctx, f)
+		if err != nil {
+			originalT.Fatalf("Failed to create the state store of feature %q: %v", f.Name, err)
+		}
+		f.State = s
 	}
 	ctx = state.ContextWith(ctx, f.State)
 	ctx = feature.ContextWith(ctx, f)
+	if mr.rand != nil {
+		// The features of an environment may run in parallel, each draws its
+		// names from its own Rand.
+		ctx = feature.ContextWithRand(ctx, mr.rand.DeriveFor(f.Name))
+	}
 
 	stepsByTiming := categorizeSteps(f.Steps)
 
@@ -410,24 +720,42 @@ func (mr *MagicEnvironment) test(ctx context.Context, originalT *testing.T, f *f
 			t.Parallel()
 		}
 
+		// The groups of a feature run before it, each in a slot of its own,
+		// so that their steps are limited too and a slot is never held while
+		// waiting for another.
+		if mr.featureSlots != nil {
+			wait := mr.featureSlots.acquire()
+			defer mr.featureSlots.release()
+			t.Logf("Waited %s for one of the --rekt.max-parallel-features=%d slots", wait, cap(mr.featureSlots))
+			milestone.EmitTestQueued(mr.milestones, f.Name, wait, t)
+		}
+
+		if !isGroup(ctx) {
+			start := time.Now()
+			t.Cleanup(func() {
+				mr.timings.record(f.Name, time.Since(start))
+			})
+		}
+
 		for _, timing := range feature.Timings() {
 			steps := feature.Steps(stepsByTiming[timing])
 
 			// Special case for teardown timing
+			var diagnostics feature.Steps
 			if timing == feature.Teardown {
 				if skip {
-					if mr.teardownOnFail {
-						// Prepend logging steps to the teardown phase when a previous timing failed.
-						steps = append(mr.loggingSteps(), steps...)
-					} else {
+					// Log and snapshot what the previous timings left behind,
+					// before the teardown steps delete it.
+					diagnostics = mr.loggingSteps()
+					if !mr.teardownOnFail {
 						// When not doing teardown only execute logging steps.
-						steps = mr.loggingSteps()
+						steps = nil
 					}
 				}
 				skip = skipTeardown
 			}
 
-			originalT.Logf("Running %d steps for timing:\n%s\n\n", len(steps), steps.String())
+			originalT.Logf("Running %d steps for timing:\n%s\n\n", len(diagnostics)+len(steps), append(diagnostics[:len(diagnostics):len(diagnostics)], steps...).String())
 
 			// aggregator aggregates steps results (success or failure) for a single timing.
 			// It used for handling the prerequisite logic.
@@ -441,15 +769,19 @@ func (mr *MagicEnvironment) test(ctx context.Context, originalT *testing.T, f *f
 					return
 				}
 
-				for _, s := range steps {
-					s := s
-					if mr.shouldFail(&s) && timing != feature.Prerequisite {
-						mr.execute(ctx, t, f, &s, aggregator)
-					} else {
-						mr.executeOptional(ctx, t, f, &s, aggregator)
-					}
+				// The logging steps complete before any teardown step starts.
+				if len(diagnostics) > 0 {
+					mr.executeStepsInOrder(ctx, t, f, diagnostics, aggregator)
 				}
+
+				mr.executeSteps(ctx, t, f, steps, aggregator)
 			})
+			if timing == feature.Teardown {
+				// The parallel teardown steps are done once the timing sub
+				// test returned.
+				mr.executeDeferredTeardown(ctx, t, f, aggregator)
+			}
+			recordFailedSteps(ctx, timing, aggregator.Failed())
 
 			// If any step at timing feature.Prerequisite failed, we should skip the feature.
 			if timing == feature.Prerequisite {
@@ -19,6 +19,7 @@ package environment
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -26,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/logging"
 
 	"knative.dev/reconciler-test/pkg/milestone"
 )
@@ -83,7 +85,60 @@ func withNamespaceTransformFunc(ctx context.Context, transforms ...NamespaceTran
 	return context.WithValue(ctx, namespaceTransformFuncsKey{}, r)
 }
 
-// CreateNamespaceIfNeeded creates a new namespace if it does not exist.
+// Annotations stamped on the test namespaces, for the rekt-janitor command to
+// delete the namespaces left behind once they expired.
+const (
+	// NamespaceCreatedAtAnnotation is the creation time of the namespace, in
+	// RFC 3339 format.
+	NamespaceCreatedAtAnnotation = "rekt.knative.dev/created-at"
+	// NamespaceTTLAnnotation is how long the namespace is kept after its
+	// creation, as a Go duration.
+	NamespaceTTLAnnotation = "rekt.knative.dev/ttl"
+
+	// DefaultNamespaceTTL is the TTL of the test namespaces when not set with
+	// WithNamespaceTTL or the rekt.namespace-ttl flag.
+	DefaultNamespaceTTL = 24 * time.Hour
+)
+
+type namespaceTTLKey struct{}
+
+// WithNamespaceTTL is an environment option to override how long the test
+// namespace is kept before the rekt-janitor command can delete it, set by the
+// rekt.namespace-ttl flag.
+func WithNamespaceTTL(ttl time.Duration) EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		return withNamespaceTTL(ctx, ttl), nil
+	}
+}
+
+func withNamespaceTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, namespaceTTLKey{}, ttl)
+}
+
+func getNamespaceTTL(ctx context.Context) time.Duration {
+	if ttl, ok := ctx.Value(namespaceTTLKey{}).(time.Duration); ok && ttl > 0 {
+		return ttl
+	}
+	return DefaultNamespaceTTL
+}
+
+type defaultServiceAccountKey struct{}
+
+// withDefaultServiceAccount makes CreateNamespaceIfNeeded create the default
+// ServiceAccount of the namespace, for control planes without the
+// kube-controller-manager that normally creates it.
+func withDefaultServiceAccount(ctx context.Context) context.Context {
+	return context.WithValue(ctx, defaultServiceAccountKey{}, true)
+}
+
+func createsDefaultServiceAccount(ctx context.Context) bool {
+	create, _ := ctx.Value(defaultServiceAccountKey{}).(bool)
+	return create
+}
+
+// CreateNamespaceIfNeeded creates a new namespace if it does not exist, with
+// its quota, limit range and network policy. They are not created in an
+// existing namespace.
 func (mr *MagicEnvironment) CreateNamespaceIfNeeded() error {
 	c := kubeclient.Get(mr.c)
 	_, err := c.CoreV1().Namespaces().Get(context.Background(), mr.namespace, metav1.GetOptions{})
@@ -94,104 +149,159 @@ func (mr *MagicEnvironment) CreateNamespaceIfNeeded() error {
 		}
 
 		// Namespace was not found, try to create it.
-
-		nsSpec := &corev1.Namespace{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:        mr.namespace,
-				Annotations: map[string]string{},
-				Labels: map[string]string{
-					"app.kubernetes.io/component": "reconciler-test",
-					"app.kubernetes.io/name":      "reconciler-test",
-				},
-			},
+		if err := createNamespace(mr.c, mr.namespace); err != nil {
+			return err
 		}
+		mr.namespaceCreated = true
+		mr.milestones.NamespaceCreated(mr.namespace)
 
-		if cfg := GetIstioConfig(mr.c); cfg.Enabled {
-			withIstioNamespaceLabel(nsSpec)
+		if err := prepareNamespace(mr.c, mr.namespace, mr.imagePullSecretNamespace, mr.imagePullSecretName); err != nil {
+			return err
 		}
+		return mr.createNamespaceGuards()
+	}
 
-		for _, nsTransform := range getNamespaceTransformFuncs(mr.c) {
-			if err := nsTransform(nsSpec); err != nil {
-				return fmt.Errorf("namespace transform function failed: %w", err)
-			}
-		}
+	if mr.hasNamespaceGuards() {
+		// The namespace isn't ours to change, like the namespace set with
+		// WithNamespace or the one an upgrade is handed over in.
+		logging.FromContext(mr.c).Warnf("Namespace %s already exists, its quota, limit range and network policy are not created", mr.namespace)
+	}
+	return nil
+}
 
-		_, err = c.CoreV1().Namespaces().Create(context.Background(), nsSpec, metav1.CreateOptions{})
+// createNamespace creates the namespace with the reconciler-test labels, the
+// creation time and TTL annotations, and the namespace transforms of ctx
+// applied.
+func createNamespace(ctx context.Context, namespace string) error {
+	nsSpec := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namespace,
+			Annotations: map[string]string{
+				NamespaceCreatedAtAnnotation: time.Now().UTC().Format(time.RFC3339),
+				NamespaceTTLAnnotation:       getNamespaceTTL(ctx).String(),
+			},
+			Labels: map[string]string{
+				"app.kubernetes.io/component": "reconciler-test",
+				"app.kubernetes.io/name":      "reconciler-test",
+			},
+		},
+	}
 
-		if err != nil {
-			return fmt.Errorf("failed to create Namespace: %s; %v", mr.namespace, err)
-		}
-		mr.namespaceCreated = true
-		mr.milestones.NamespaceCreated(mr.namespace)
+	if cfg := GetIstioConfig(ctx); cfg.Enabled {
+		withIstioNamespaceLabel(nsSpec)
+	}
 
-		interval, timeout := PollTimingsFromContext(mr.c)
-
-		// https://github.com/kubernetes/kubernetes/issues/66689
-		// We can only start creating pods after the default ServiceAccount is created by the kube-controller-manager.
-		var sa *corev1.ServiceAccount
-		if err := wait.PollImmediate(interval, timeout, func() (bool, error) {
-			sas := c.CoreV1().ServiceAccounts(mr.namespace)
-			if sa, err = sas.Get(context.Background(), "default", metav1.GetOptions{}); err == nil {
-				return true, nil
-			}
-			return false, nil
-		}); err != nil {
-			return fmt.Errorf("the default ServiceAccount was not created for the Namespace: %s", mr.namespace)
+	for _, nsTransform := range getNamespaceTransformFuncs(ctx) {
+		if err := nsTransform(nsSpec); err != nil {
+			return fmt.Errorf("namespace transform function failed: %w", err)
 		}
+	}
 
-		srcSecret, err := c.CoreV1().Secrets(mr.imagePullSecretNamespace).Get(context.Background(), mr.imagePullSecretName, metav1.GetOptions{})
-		if err != nil {
-			if apierrors.IsNotFound(err) {
-				// Image pull secret doesn't exist, so no need to continue
-				return nil
-			}
-			return fmt.Errorf("error retrieving %s/%s secret: %s", mr.imagePullSecretNamespace, mr.imagePullSecretName, err)
-		}
+	_, err := kubeclient.Get(ctx).CoreV1().Namespaces().Create(context.Background(), nsSpec, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create Namespace: %s; %v", namespace, err)
+	}
+	return nil
+}
 
-		// If image pull secret exists in the default namespace, copy it over to the new namespace
-		_, err = c.CoreV1().Secrets(mr.namespace).Create(
-			context.Background(),
-			&corev1.Secret{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: mr.imagePullSecretName,
-				},
-				Data: srcSecret.Data,
-				Type: srcSecret.Type,
-			},
-			metav1.CreateOptions{})
+// stampNamespace sets the creation time annotation of the namespace to now.
+func stampNamespace(ctx context.Context, namespace string) error {
+	patch := `{"metadata":{"annotations":{"` + NamespaceCreatedAtAnnotation + `":"` + time.Now().UTC().Format(time.RFC3339) + `"}}}`
+	_, err := kubeclient.Get(ctx).CoreV1().Namespaces().Patch(context.Background(), namespace, types.MergePatchType,
+		[]byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+// prepareNamespace waits for the default ServiceAccount of a new namespace,
+// creating it when needed, and adds the image pull secret to it, if the
+// secret exists.
+func prepareNamespace(ctx context.Context, namespace, imagePullSecretNamespace, imagePullSecretName string) error {
+	c := kubeclient.Get(ctx)
 
+	if createsDefaultServiceAccount(ctx) {
+		_, err := c.CoreV1().ServiceAccounts(namespace).Create(context.Background(), &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		}, metav1.CreateOptions{})
 		if err != nil && !apierrors.IsAlreadyExists(err) {
-			return fmt.Errorf("error copying the image pull Secret: %s", err)
+			return fmt.Errorf("failed to create the default ServiceAccount for the Namespace: %s; %v", namespace, err)
 		}
+	}
 
-		for _, secret := range sa.ImagePullSecrets {
-			if secret.Name == mr.imagePullSecretName {
-				return nil
-			}
+	interval, timeout := PollTimingsFromContext(ctx)
+
+	// https://github.com/kubernetes/kubernetes/issues/66689
+	// We can only start creating pods after the default ServiceAccount is created by the kube-controller-manager.
+	var sa *corev1.ServiceAccount
+	if err := wait.PollImmediate(interval, timeout, func() (bool, error) {
+		var err error
+		sas := c.CoreV1().ServiceAccounts(namespace)
+		if sa, err = sas.Get(context.Background(), "default", metav1.GetOptions{}); err == nil {
+			return true, nil
 		}
+		return false, nil
+	}); err != nil {
+		return fmt.Errorf("the default ServiceAccount was not created for the Namespace: %s", namespace)
+	}
 
-		// Prevent overwriting existing imagePullSecrets
-		patch := `[{"op":"add","path":"/imagePullSecrets/-","value":{"name":"` + mr.imagePullSecretName + `"}}]`
-		if len(sa.ImagePullSecrets) == 0 {
-			patch = `[{"op":"add","path":"/imagePullSecrets","value":[{"name":"` + mr.imagePullSecretName + `"}]}]`
+	srcSecret, err := c.CoreV1().Secrets(imagePullSecretNamespace).Get(context.Background(), imagePullSecretName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// Image pull secret doesn't exist, so no need to continue
+			return nil
 		}
+		return fmt.Errorf("error retrieving %s/%s secret: %s", imagePullSecretNamespace, imagePullSecretName, err)
+	}
 
-		_, err = c.CoreV1().ServiceAccounts(mr.namespace).Patch(context.Background(), sa.Name, types.JSONPatchType,
-			[]byte(patch), metav1.PatchOptions{})
-		if err != nil {
-			return fmt.Errorf("patch failed on NS/SA (%s/%s): %w",
-				mr.namespace, sa.Name, err)
+	// If image pull secret exists in the default namespace, copy it over to the new namespace
+	_, err = c.CoreV1().Secrets(namespace).Create(
+		context.Background(),
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: imagePullSecretName,
+			},
+			Data: srcSecret.Data,
+			Type: srcSecret.Type,
+		},
+		metav1.CreateOptions{})
+
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("error copying the image pull Secret: %s", err)
+	}
+
+	for _, secret := range sa.ImagePullSecrets {
+		if secret.Name == imagePullSecretName {
+			return nil
 		}
 	}
 
+	// Prevent overwriting existing imagePullSecrets
+	patch := `[{"op":"add","path":"/imagePullSecrets/-","value":{"name":"` + imagePullSecretName + `"}}]`
+	if len(sa.ImagePullSecrets) == 0 {
+		patch = `[{"op":"add","path":"/imagePullSecrets","value":[{"name":"` + imagePullSecretName + `"}]}]`
+	}
+
+	_, err = c.CoreV1().ServiceAccounts(namespace).Patch(context.Background(), sa.Name, types.JSONPatchType,
+		[]byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("patch failed on NS/SA (%s/%s): %w",
+			namespace, sa.Name, err)
+	}
 	return nil
 }
 
 func (mr *MagicEnvironment) DeleteNamespaceIfNeeded(result milestone.Result) error {
+	if mr.namespacePool != nil {
+		return mr.releasePooledNamespace(result)
+	}
 	if (result.Failed() && !mr.teardownOnFail) || !mr.namespaceCreated {
 		return nil
 	}
+	return mr.deleteNamespace()
+}
 
+// deleteNamespace deletes the namespace of the environment, if it still
+// exists.
+func (mr *MagicEnvironment) deleteNamespace() error {
 	c := kubeclient.Get(mr.c)
 
 	_, err := c.CoreV1().Namespaces().Get(context.Background(), mr.namespace, metav1.GetOptions{})
@@ -0,0 +1,58 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import "time"
+
+// featureSlots is a semaphore limiting the number of features tested at the
+// same time, as set by the rekt.max-parallel-features flag. A nil
+// featureSlots doesn't limit them.
+//
+// -test.parallel bounds the parallel sub tests running at once, not the
+// features in flight, since a feature waiting for its steps doesn't hold a
+// slot. A feature waiting for one of the featureSlots holds its -test.parallel
+// slot though, so the steps of the features holding one run within the slot of
+// their feature, see executeSteps.
+//
+// The slot is acquired once the feature starts: the namespace of its
+// environment already exists. The groups of a feature take a slot each, as
+// they run on their own.
+type featureSlots chan struct{}
+
+func newFeatureSlots(n int) featureSlots {
+	if n <= 0 {
+		return nil
+	}
+	return make(featureSlots, n)
+}
+
+// acquire waits for a free slot and returns how long it waited.
+func (s featureSlots) acquire() time.Duration {
+	if s == nil {
+		return 0
+	}
+	start := time.Now()
+	s <- struct{}{}
+	return time.Since(start)
+}
+
+func (s featureSlots) release() {
+	if s == nil {
+		return
+	}
+	<-s
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/milestone"
+)
+
+// queueEmitter records the TestQueued milestone events.
+type queueEmitter struct {
+	milestone.Emitter
+
+	mu    sync.Mutex
+	waits map[string]time.Duration
+}
+
+func (e *queueEmitter) TestQueued(feature string, wait time.Duration, t feature.T) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.waits[feature] = wait
+}
+
+func TestMaxParallelFeatures(t *testing.T) {
+	// The features waiting for a slot hold -test.parallel slots, the steps of
+	// the running features must not wait for them.
+	parallel, _ := strconv.Atoi(flag.Lookup("test.parallel").Value.String())
+
+	emitter := &queueEmitter{Emitter: milestone.Compose(), waits: make(map[string]time.Duration)}
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:            feature.All,
+			s:            feature.Any,
+			featureMatch: regexp.MustCompile(""),
+			milestones:   emitter,
+			stateStore:   newKVStore,
+			featureSlots: newFeatureSlots(2),
+		},
+	}
+
+	var running, maxRunning atomic.Int32
+	t.Run("features", func(t *testing.T) {
+		for i := 0; i < 6; i++ {
+			f := feature.NewFeatureNamed(fmt.Sprintf("Feature-%d", i))
+			// Parallel steps of a feature don't take more slots.
+			for _, step := range []string{"a", "b"} {
+				f.Setup(step, func(ctx context.Context, t feature.T) {
+					n := running.Add(1)
+					defer running.Add(-1)
+					for {
+						m := maxRunning.Load()
+						if n <= m || maxRunning.CompareAndSwap(m, n) {
+							break
+						}
+					}
+					time.Sleep(50 * time.Millisecond)
+				})
+			}
+			mr.ParallelTest(context.Background(), t, f)
+		}
+	})
+
+	require.Len(t, emitter.waits, 6)
+	if parallel < 2 {
+		require.EqualValues(t, 2, maxRunning.Load(), "1 feature with 2 steps")
+		return
+	}
+	require.EqualValues(t, 4, maxRunning.Load(), "2 features with 2 steps each")
+	if parallel == 2 {
+		// -test.parallel already limits the features to 2.
+		return
+	}
+	queued := 0
+	for _, wait := range emitter.waits {
+		if wait >= 40*time.Millisecond {
+			queued++
+		}
+	}
+	require.NotZero(t, queued, "features waited for a slot: %v", emitter.waits)
+}
+
+func TestMaxParallelFeaturesGroups(t *testing.T) {
+	emitter := &queueEmitter{Emitter: milestone.Compose(), waits: make(map[string]time.Duration)}
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:            feature.All,
+			s:            feature.Any,
+			featureMatch: regexp.MustCompile(""),
+			milestones:   emitter,
+			stateStore:   newKVStore,
+			featureSlots: newFeatureSlots(1),
+		},
+	}
+
+	var running, maxRunning atomic.Int32
+	steps := func(f *feature.Feature) {
+		for _, step := range []string{"a", "b"} {
+			f.Setup(step, func(ctx context.Context, t feature.T) {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					m := maxRunning.Load()
+					if n <= m || maxRunning.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+			})
+		}
+	}
+	t.Run("features", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			f := feature.NewFeatureNamed(fmt.Sprintf("Feature-%d", i))
+			steps(f)
+			g := feature.NewFeatureNamed(fmt.Sprintf("Group-%d", i))
+			steps(g)
+			f.GroupF(g)
+			mr.ParallelTest(context.Background(), t, f)
+		}
+	})
+
+	require.Len(t, emitter.waits, 4, "the groups take a slot: %v", emitter.waits)
+	require.EqualValues(t, 2, maxRunning.Load(), "1 feature with 2 steps")
+}
+
+func TestFeatureSlots(t *testing.T) {
+	var unlimited featureSlots
+	require.Zero(t, unlimited.acquire())
+	unlimited.release()
+	require.Nil(t, newFeatureSlots(0))
+
+	s := newFeatureSlots(1)
+	require.Less(t, s.acquire(), 10*time.Millisecond)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		s.release()
+	}()
+	require.GreaterOrEqual(t, s.acquire(), 15*time.Millisecond)
+	s.release()
+}
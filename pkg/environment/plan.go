@@ -0,0 +1,195 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"knative.dev/reconciler-test/pkg/feature"
+)
+
+// planDir is the directory, relative to $ARTIFACTS, where the dry-run plans
+// are written.
+const planDir = "rekt-plan"
+
+// featurePlan describes what testing a feature would do.
+type featurePlan struct {
+	Name    string         `json:"name"`
+	Tags    []string       `json:"tags,omitempty"`
+	Groups  []*featurePlan `json:"groups,omitempty"`
+	Timings []timingPlan   `json:"timings"`
+}
+
+type timingPlan struct {
+	Timing feature.Timing `json:"timing"`
+	Steps  []stepPlan     `json:"steps"`
+}
+
+type stepPlan struct {
+	feature.Step
+	// Optional is true when a failure of the step doesn't fail the feature
+	// given the environment state and level.
+	Optional bool `json:"optional"`
+}
+
+func (mr *MagicEnvironment) planFeature(f *feature.Feature) *featurePlan {
+	p := &featurePlan{
+		Name: f.Name,
+		Tags: f.GetTags(),
+	}
+	for _, g := range f.GetGroups() {
+		p.Groups = append(p.Groups, mr.planFeature(g))
+	}
+	stepsByTiming := categorizeSteps(f.Steps)
+	for _, timing := range feature.Timings() {
+		tp := timingPlan{Timing: timing, Steps: []stepPlan{}}
+		for _, s := range stepsByTiming[timing] {
+			s := s
+			tp.Steps = append(tp.Steps, stepPlan{
+				Step:     s,
+				Optional: !mr.shouldFail(&s) || timing == feature.Prerequisite,
+			})
+		}
+		p.Timings = append(p.Timings, tp)
+	}
+	return p
+}
+
+// writePlan writes the plan of the feature to $ARTIFACTS/rekt-plan/<test>.*
+// as Markdown, JSON and Graphviz files instead of testing it.
+func (mr *MagicEnvironment) writePlan(originalT *testing.T, f *feature.Feature) {
+	originalT.Helper()
+
+	p := mr.planFeature(f)
+	md := p.markdown()
+
+	originalT.Run(f.Name, func(t *testing.T) {
+		t.Log("Dry run, feature plan:\n", md)
+
+		jsonPlan, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := filepath.Join(artifactsDir(), planDir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		// The test name is unique, even when features with the same name are
+		// tested by the same test.
+		base := filepath.Join(dir, feature.MakeK8sNamePrefix(t.Name()))
+		for ext, content := range map[string]string{
+			".md":   md,
+			".json": string(jsonPlan),
+			".dot":  p.graphviz(),
+		} {
+			if err := os.WriteFile(base+ext, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		t.Logf("Feature plan written to %s.{md,json,dot}", base)
+	})
+}
+
+func (p *featurePlan) markdown() string {
+	sb := &strings.Builder{}
+	p.writeMarkdown(sb, 1)
+	return sb.String()
+}
+
+func (p *featurePlan) writeMarkdown(sb *strings.Builder, depth int) {
+	fmt.Fprintf(sb, "%s %s\n\n", strings.Repeat("#", depth), p.Name)
+	if len(p.Tags) > 0 {
+		fmt.Fprintf(sb, "Tags: %s\n\n", strings.Join(p.Tags, ", "))
+	}
+	for i, g := range p.Groups {
+		fmt.Fprintf(sb, "Group %d runs before the feature steps.\n\n", i+1)
+		g.writeMarkdown(sb, depth+1)
+	}
+	for _, tp := range p.Timings {
+		if len(tp.Steps) == 0 {
+			continue
+		}
+		fmt.Fprintf(sb, "%s %s\n\n", strings.Repeat("#", depth+1), tp.Timing)
+		sb.WriteString("| Step | State | Level | After | Optional |\n")
+		sb.WriteString("| ---- | ----- | ----- | ----- | -------- |\n")
+		for _, s := range tp.Steps {
+			fmt.Fprintf(sb, "| %s | %s | %s | %s | %t |\n",
+				escapeMarkdown(s.Name), s.S, s.L, escapeMarkdown(strings.Join(s.Deps, ", ")), s.Optional)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func (p *featurePlan) graphviz() string {
+	sb := &strings.Builder{}
+	sb.WriteString("digraph plan {\n  rankdir=LR;\n  node [shape=box];\n")
+	p.writeGraphviz(sb, p.Name)
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// writeGraphviz writes a cluster for the feature, with a node for each timing
+// linked in execution order, and the step nodes linked to their timing or to
+// the steps they depend on.
+func (p *featurePlan) writeGraphviz(sb *strings.Builder, id string) {
+	fmt.Fprintf(sb, "  subgraph %q {\n    label=%q;\n", "cluster_"+id, p.Name)
+	for _, tp := range p.Timings {
+		timingID := id + "/" + tp.Timing.String()
+		fmt.Fprintf(sb, "    %q [label=%q, shape=ellipse];\n", timingID, tp.Timing.String())
+		for _, s := range tp.Steps {
+			fmt.Fprintf(sb, "    %q [label=%q];\n", timingID+"/"+s.Name, fmt.Sprintf("%s\n%s/%s", s.Name, s.S, s.L))
+		}
+	}
+	sb.WriteString("  }\n")
+
+	for i, g := range p.Groups {
+		groupID := fmt.Sprintf("%s/group-%d", id, i+1)
+		g.writeGraphviz(sb, groupID)
+		fmt.Fprintf(sb, "  %q -> %q;\n", groupID+"/"+feature.Teardown.String(), id+"/"+feature.Prerequisite.String())
+	}
+
+	for i, tp := range p.Timings {
+		timingID := id + "/" + tp.Timing.String()
+		if i > 0 {
+			fmt.Fprintf(sb, "  %q -> %q;\n", id+"/"+p.Timings[i-1].Timing.String(), timingID)
+		}
+		for _, s := range tp.Steps {
+			if len(s.Deps) == 0 {
+				fmt.Fprintf(sb, "  %q -> %q;\n", timingID, timingID+"/"+s.Name)
+			}
+			for _, d := range s.Deps {
+				fmt.Fprintf(sb, "  %q -> %q;\n", timingID+"/"+d, timingID+"/"+s.Name)
+			}
+		}
+	}
+}
+
+// noopImageProducer is used when no image is built, in dry-run mode or with
+// fake clients.
+func noopImageProducer(_ context.Context, pack string) (string, error) {
+	return "ko://" + pack, nil
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	testlog "knative.dev/reconciler-test/pkg/logging"
+	"knative.dev/reconciler-test/pkg/milestone"
+)
+
+func TestDryRun(t *testing.T) {
+	artifacts := t.TempDir()
+	t.Setenv("ARTIFACTS", artifacts)
+
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:            feature.Must,
+			s:            feature.Any,
+			featureMatch: regexp.MustCompile(""),
+			milestones:   milestone.Compose(),
+			dryRun:       true,
+		},
+	}
+
+	ran := false
+	step := func(ctx context.Context, t feature.T) { ran = true }
+
+	f := feature.NewFeatureNamed("Broker delivery")
+	f.Tags("tls")
+	f.Group("install", func(f *feature.Feature) {
+		f.Setup("install broker", step)
+	})
+	f.Setup("install trigger", step)
+	f.Setup("install source", step, feature.After("install trigger"))
+	f.Stable("broker").
+		Must("delivers events", step).
+		May("orders events", step)
+	f.Teardown("delete source", step)
+
+	mr.runFeature(context.Background(), t, f)
+
+	require.False(t, ran)
+
+	md, err := os.ReadFile(filepath.Join(artifacts, planDir, "test-dry-run-broker-delivery.md"))
+	require.NoError(t, err)
+	require.Equal(t, `# Broker delivery
+
+Tags: tls
+
+Group 1 runs before the feature steps.
+
+## install
+
+### Setup
+
+| Step | State | Level | After | Optional |
+| ---- | ----- | ----- | ----- | -------- |
+| install broker | Any | All |  | false |
+
+## Setup
+
+| Step | State | Level | After | Optional |
+| ---- | ----- | ----- | ----- | -------- |
+| install trigger | Any | All |  | false |
+| install source | Any | All | install trigger | false |
+
+## Assert
+
+| Step | State | Level | After | Optional |
+| ---- | ----- | ----- | ----- | -------- |
+| broker delivers events | Stable | MUST |  | false |
+| broker orders events | Stable | MAY |  | true |
+
+## Teardown
+
+| Step | State | Level | After | Optional |
+| ---- | ----- | ----- | ----- | -------- |
+| delete source | Any | All |  | false |
+
+`, string(md))
+
+	b, err := os.ReadFile(filepath.Join(artifacts, planDir, "test-dry-run-broker-delivery.json"))
+	require.NoError(t, err)
+	var p map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &p))
+	require.Equal(t, "Broker delivery", p["name"])
+	require.Len(t, p["groups"], 1)
+	require.Len(t, p["timings"], len(feature.Timings()))
+
+	dot, err := os.ReadFile(filepath.Join(artifacts, planDir, "test-dry-run-broker-delivery.dot"))
+	require.NoError(t, err)
+	require.Contains(t, string(dot), `"Broker delivery/Setup/install trigger" -> "Broker delivery/Setup/install source";`)
+	require.Contains(t, string(dot), `"Broker delivery/group-1/Teardown" -> "Broker delivery/Prerequisite";`)
+}
+
+func TestDryRunSameFeatureName(t *testing.T) {
+	artifacts := t.TempDir()
+	t.Setenv("ARTIFACTS", artifacts)
+
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:            feature.All,
+			s:            feature.Any,
+			featureMatch: regexp.MustCompile(""),
+			milestones:   milestone.Compose(),
+			dryRun:       true,
+		},
+	}
+
+	for _, step := range []string{"first", "second"} {
+		f := feature.NewFeatureNamed("Broker delivery")
+		f.Setup(step, func(ctx context.Context, t feature.T) {})
+		mr.runFeature(context.Background(), t, f)
+	}
+
+	for path, step := range map[string]string{
+		"test-dry-run-same-feature-name-broker-delivery.md":    "first",
+		"test-dry-run-same-feature-name-broker-delivery-01.md": "second",
+	} {
+		md, err := os.ReadFile(filepath.Join(artifacts, planDir, path))
+		require.NoError(t, err)
+		require.Contains(t, string(md), "| "+step+" |")
+	}
+}
+
+func TestDryRunUpgrade(t *testing.T) {
+	artifacts := t.TempDir()
+	t.Setenv("ARTIFACTS", artifacts)
+
+	global := &MagicGlobalEnvironment{
+		RequirementLevel: feature.All,
+		FeatureState:     feature.Any,
+		FeatureMatch:     regexp.MustCompile(""),
+		c:                testlog.WithTestLogger(context.Background(), t),
+		dryRun:           true,
+	}
+
+	ran := false
+	step := func(ctx context.Context, t feature.T) { ran = true }
+	u := feature.NewUpgrade("Broker")
+	u.PreUpgrade.Setup("install broker", step)
+	u.PostUpgrade.Assert("broker is ready", step)
+	upgrade := func(ctx context.Context, t feature.T) { ran = true }
+
+	for _, phase := range []UpgradePhase{UpgradeAllPhases, UpgradePrePhase, UpgradePostPhase} {
+		global.UpgradePhase = phase
+		t.Run(string(phase), func(t *testing.T) {
+			TestUpgrade(t, global, u, upgrade)
+		})
+	}
+
+	require.False(t, ran, "the steps and the upgrade function don't run")
+	_, err := os.Stat(filepath.Join(artifacts, upgradeDir))
+	require.True(t, os.IsNotExist(err), "nothing is recorded: %v", err)
+	plans, err := filepath.Glob(filepath.Join(artifacts, planDir, "*.md"))
+	require.NoError(t, err)
+	// The pre-upgrade feature of the all and pre phases, the post-upgrade
+	// feature of the all and post phases.
+	require.Len(t, plans, 4)
+}
+
+func TestDryRunUpgradeSelectedAsAWhole(t *testing.T) {
+	shard := Shard{Count: 8}
+	shard.Index = shard.Of("Broker")
+	other := Shard{Index: shard.Index%shard.Count + 1, Count: shard.Count}
+	require.False(t, shard.Includes("Broker PreUpgrade") && shard.Includes("Broker PostUpgrade"),
+		"a phase is in another shard than the upgrade")
+
+	tests := map[string]struct {
+		match *regexp.Regexp
+		tags  *feature.TagExpression
+		shard Shard
+		plans int
+	}{
+		"upgrade name": {
+			match: regexp.MustCompile("^Broker$"),
+			plans: 2,
+		},
+		"phase name": {
+			match: regexp.MustCompile("PostUpgrade"),
+			plans: 2,
+		},
+		"no match": {
+			match: regexp.MustCompile("Trigger"),
+		},
+		"tags of a phase": {
+			tags:  feature.MustParseTagExpression("broker"),
+			plans: 2,
+		},
+		"tags of no phase": {
+			tags: feature.MustParseTagExpression("channel"),
+		},
+		"shard of the upgrade": {
+			shard: shard,
+			plans: 2,
+		},
+		"other shard": {
+			shard: other,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			artifacts := t.TempDir()
+			t.Setenv("ARTIFACTS", artifacts)
+
+			match := tc.match
+			if match == nil {
+				match = regexp.MustCompile("")
+			}
+			global := &MagicGlobalEnvironment{
+				RequirementLevel: feature.All,
+				FeatureState:     feature.Any,
+				FeatureMatch:     match,
+				FeatureTags:      tc.tags,
+				c:                testlog.WithTestLogger(context.Background(), t),
+				dryRun:           true,
+				shard:            tc.shard,
+			}
+
+			step := func(ctx context.Context, t feature.T) {}
+			u := feature.NewUpgrade("Broker")
+			u.PreUpgrade.Setup("install broker", step)
+			u.PreUpgrade.Tags("broker")
+			u.PostUpgrade.Assert("broker is ready", step)
+
+			t.Run("upgrade", func(t *testing.T) {
+				TestUpgrade(t, global, u, nil)
+			})
+
+			plans, err := filepath.Glob(filepath.Join(artifacts, planDir, "*.md"))
+			require.NoError(t, err)
+			require.Len(t, plans, tc.plans)
+		})
+	}
+}
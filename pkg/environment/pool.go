@@ -0,0 +1,345 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+	"knative.dev/pkg/logging"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/milestone"
+)
+
+// Default image pull secret of the environments.
+const (
+	defaultImagePullSecretName      = "kn-test-image-pull-secret"
+	defaultImagePullSecretNamespace = "default"
+)
+
+// NamespacePoolReuse is what happens to the namespaces of a namespace pool
+// once the environment using them finishes.
+type NamespacePoolReuse int
+
+const (
+	// NamespacePoolNeverReuse deletes the namespaces, the pool creates a new
+	// one every time a namespace is handed out.
+	NamespacePoolNeverReuse NamespacePoolReuse = iota
+	// NamespacePoolReuseAfterScrub deletes the resources of the namespaces
+	// and hands them out again.
+	NamespacePoolReuseAfterScrub
+)
+
+var namespacePoolReuses = map[NamespacePoolReuse]string{
+	NamespacePoolNeverReuse:      "never",
+	NamespacePoolReuseAfterScrub: "scrub",
+}
+
+func (r NamespacePoolReuse) String() string {
+	if name, ok := namespacePoolReuses[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("NamespacePoolReuse(%d)", int(r))
+}
+
+// Set implements flag.Value.
+func (r *NamespacePoolReuse) Set(s string) error {
+	for reuse, name := range namespacePoolReuses {
+		if strings.EqualFold(s, name) {
+			*r = reuse
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown namespace pool reuse policy %q, expected never or scrub", s)
+}
+
+// DrainNamespacePool deletes the namespaces of the namespace pool of the
+// global environment that weren't handed out, it is meant to be called from
+// TestMain once the tests ran. It waits for the namespaces being created.
+func DrainNamespacePool(global GlobalEnvironment) {
+	if mr, ok := global.(*MagicGlobalEnvironment); ok && mr.namespacePool != nil {
+		mr.namespacePool.drain()
+	}
+}
+
+// namespacePool creates namespaces in the background, with the namespace
+// transforms of the global environment applied, to hand them out to the
+// environments.
+type namespacePool struct {
+	c     context.Context
+	reuse NamespacePoolReuse
+	rand  *feature.Rand
+	ready chan pooledNamespace
+
+	mu sync.Mutex
+	// idle counts the namespaces ready or being created, the pool owns at
+	// most cap(ready) namespaces.
+	idle    int
+	drained bool
+	wg      sync.WaitGroup
+}
+
+type pooledNamespace struct {
+	name string
+	err  error
+	// reused is set for the scrubbed namespaces handed back to the pool.
+	reused bool
+}
+
+// newNamespacePool starts creating size namespaces, it returns nil when size
+// is not positive. The names of the namespaces come from their own Rand
+// derived from r, since they are drawn concurrently with the environments.
+func newNamespacePool(ctx context.Context, size int, reuse NamespacePoolReuse, r *feature.Rand) *namespacePool {
+	if size <= 0 {
+		return nil
+	}
+	if r == nil {
+		r = feature.DefaultRand()
+	}
+	p := &namespacePool{
+		c:     ctx,
+		reuse: reuse,
+		rand:  r.DeriveFor("namespace-pool"),
+		ready: make(chan pooledNamespace, size),
+	}
+	for i := 0; i < size; i++ {
+		p.fill()
+	}
+	return p
+}
+
+// fill creates a namespace in the background.
+func (p *namespacePool) fill() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.drained {
+		return
+	}
+	p.idle++
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		name := p.rand.MakeRandomK8sName("test")
+		p.ready <- pooledNamespace{name: name, err: p.create(name)}
+	}()
+}
+
+func (p *namespacePool) create(name string) error {
+	if err := createNamespace(p.c, name); err != nil {
+		return err
+	}
+	if err := prepareNamespace(p.c, name, defaultImagePullSecretNamespace, defaultImagePullSecretName); err != nil {
+		if derr := kubeclient.Get(p.c).CoreV1().Namespaces().Delete(context.Background(), name, metav1.DeleteOptions{}); derr != nil && !apierrors.IsNotFound(derr) {
+			logging.FromContext(p.c).Warnf("Failed to delete the pooled namespace %s: %v", name, derr)
+		}
+		return err
+	}
+	return nil
+}
+
+// get hands out a namespace, waiting for one being created if needed. It
+// returns an empty name when every namespace of the pool is in use.
+func (p *namespacePool) get() (string, error) {
+	p.mu.Lock()
+	if p.drained || p.idle == 0 {
+		p.mu.Unlock()
+		return "", nil
+	}
+	p.idle--
+	p.mu.Unlock()
+
+	if p.reuse == NamespacePoolNeverReuse {
+		p.fill()
+	}
+	pn := <-p.ready
+	if pn.err != nil {
+		p.replace()
+		return "", fmt.Errorf("failed to create the pooled namespace %s: %w", pn.name, pn.err)
+	}
+	if pn.reused {
+		// The TTL of a reused namespace starts again, so that the janitor
+		// doesn't delete it while it's in use.
+		if err := stampNamespace(p.c, pn.name); err != nil {
+			p.delete(pn.name)
+			p.replace()
+			return "", fmt.Errorf("failed to stamp the reused namespace %s: %w", pn.name, err)
+		}
+	}
+	return pn.name, nil
+}
+
+// put hands a scrubbed namespace back to the pool.
+func (p *namespacePool) put(name string) {
+	p.mu.Lock()
+	if p.drained {
+		p.mu.Unlock()
+		p.delete(name)
+		return
+	}
+	p.idle++
+	p.mu.Unlock()
+	p.ready <- pooledNamespace{name: name, reused: true}
+}
+
+// replace creates a namespace in place of one handed out that doesn't come
+// back to the pool, only namespaces that are reused are replaced this way.
+func (p *namespacePool) replace() {
+	if p.reuse == NamespacePoolReuseAfterScrub {
+		p.fill()
+	}
+}
+
+func (p *namespacePool) drain() {
+	p.mu.Lock()
+	p.drained = true
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	for {
+		select {
+		case pn := <-p.ready:
+			p.delete(pn.name)
+		default:
+			return
+		}
+	}
+}
+
+func (p *namespacePool) delete(name string) {
+	err := kubeclient.Get(p.c).CoreV1().Namespaces().Delete(context.Background(), name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		logging.FromContext(p.c).Warnf("Failed to delete the pooled namespace %s: %v", name, err)
+	}
+}
+
+// namespaceFromPool hands out a namespace of the pool to the environment,
+// unless the environment has a namespace set with WithNamespace, or creates
+// its namespace differently than the pool, with another TTL, including with a quota, a limit
+// range or a network policy.
+func (mr *MagicGlobalEnvironment) namespaceFromPool(ctx context.Context, env *MagicEnvironment) error {
+	if mr.namespacePool == nil || env.dryRun || getNamespace(ctx) != "" ||
+		len(getNamespaceTransformFuncs(ctx)) != len(getNamespaceTransformFuncs(mr.c)) ||
+		getNamespaceTTL(ctx) != getNamespaceTTL(mr.c) ||
+		env.imagePullSecretName != defaultImagePullSecretName ||
+		env.imagePullSecretNamespace != defaultImagePullSecretNamespace ||
+		env.hasNamespaceGuards() {
+		return nil
+	}
+
+	name, err := mr.namespacePool.get()
+	if err != nil || name == "" {
+		return err
+	}
+	env.namespace = name
+	env.namespaceCreated = true
+	env.namespacePool = mr.namespacePool
+	return nil
+}
+
+// releasePooledNamespace deletes the namespace handed out by the pool, or
+// scrubs it and hands it back to the pool. The namespace of a failed
+// environment is kept, unless teardownOnFail is set.
+func (mr *MagicEnvironment) releasePooledNamespace(result milestone.Result) error {
+	p := mr.namespacePool
+	mr.namespacePool = nil
+
+	switch {
+	case result.Failed() && !mr.teardownOnFail:
+		p.replace()
+		return nil
+	case p.reuse == NamespacePoolReuseAfterScrub:
+		err := scrubNamespace(mr.c, mr.namespace, mr.imagePullSecretName)
+		if err == nil {
+			mr.namespaceCreated = false
+			p.put(mr.namespace)
+			return nil
+		}
+		logging.FromContext(mr.c).Warnf("Failed to scrub namespace %s, deleting it: %v", mr.namespace, err)
+	}
+	p.replace()
+	return mr.deleteNamespace()
+}
+
+// scrubNamespace deletes the resources of the namespace, except those created
+// with the namespace, and waits for them to be gone.
+func scrubNamespace(ctx context.Context, namespace, imagePullSecretName string) error {
+	resources, err := listableResources(ctx)
+	if err != nil {
+		return err
+	}
+	kept := sets.New(
+		leakKey{kind: "ServiceAccount", namespace: namespace, name: "default"},
+		leakKey{kind: "ConfigMap", namespace: namespace, name: "kube-root-ca.crt"},
+		leakKey{kind: "Secret", namespace: namespace, name: imagePullSecretName},
+	)
+
+	dc := dynamicclient.Get(ctx)
+	// remaining deletes the resources left and returns how many there are.
+	remaining := func() (int, error) {
+		n := 0
+		for _, r := range resources {
+			if !r.namespaced || ignoredResources.Has(r.GroupResource()) {
+				continue
+			}
+			client := dc.Resource(r.GroupVersionResource).Namespace(namespace)
+			l, err := client.List(ctx, metav1.ListOptions{})
+			if err != nil {
+				if ignoreListError(err) {
+					continue
+				}
+				return 0, fmt.Errorf("failed to list %s: %w", r.GroupVersionResource, err)
+			}
+			for _, obj := range l.Items {
+				key := leakKey{group: r.Group, kind: r.kind, namespace: namespace, name: obj.GetName()}
+				if kept.Has(key) || (r.kind == "Secret" && obj.Object["type"] == string(corev1.SecretTypeServiceAccountToken)) {
+					continue
+				}
+				n++
+				// Owned resources are deleted by the garbage collector.
+				if len(obj.GetOwnerReferences()) > 0 || obj.GetDeletionTimestamp() != nil {
+					continue
+				}
+				policy := metav1.DeletePropagationBackground
+				if err := client.Delete(ctx, obj.GetName(), metav1.DeleteOptions{PropagationPolicy: &policy}); err != nil && !apierrors.IsNotFound(err) {
+					return 0, fmt.Errorf("failed to delete %s %s: %w", r.kind, obj.GetName(), err)
+				}
+			}
+		}
+		return n, nil
+	}
+
+	interval, timeout := PollTimingsFromContext(ctx)
+	var left int
+	if err := wait.PollImmediate(interval, timeout, func() (bool, error) {
+		var err error
+		left, err = remaining()
+		return left == 0, err
+	}); err != nil {
+		return fmt.Errorf("%d resource(s) left in namespace %s: %w", left, namespace, err)
+	}
+	return nil
+}
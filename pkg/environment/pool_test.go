@@ -0,0 +1,127 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/environment/fakeenv"
+	testlog "knative.dev/reconciler-test/pkg/logging"
+)
+
+func TestNamespacePool(t *testing.T) {
+	tests := map[string]struct {
+		reuse environment.NamespacePoolReuse
+	}{
+		"never reuse":       {reuse: environment.NamespacePoolNeverReuse},
+		"reuse after scrub": {reuse: environment.NamespacePoolReuseAfterScrub},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := testlog.WithTestLogger(context.Background(), t)
+			global := fakeenv.NewGlobalEnvironment(ctx,
+				fakeenv.WithNamespacePool(1, tc.reuse),
+				fakeenv.WithListKinds(map[schema.GroupVersionResource]string{widgets: "WidgetList"}),
+			)
+
+			// envCtx holds the fake clients of the global environment.
+			var envCtx context.Context
+			var first string
+			t.Run("first", func(t *testing.T) {
+				ctx, env := global.Environment(environment.Managed(t))
+				envCtx, first = ctx, env.Namespace()
+				kubeclient.Get(ctx).Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+					GroupVersion: "example.dev/v1",
+					APIResources: []metav1.APIResource{
+						{Name: "widgets", Namespaced: true, Kind: "Widget", Verbs: []string{"create", "list", "delete"}},
+					},
+				}}
+
+				ns, err := kubeclient.Get(ctx).CoreV1().Namespaces().Get(ctx, first, metav1.GetOptions{})
+				require.NoError(t, err)
+				require.Equal(t, "reconciler-test", ns.Labels["app.kubernetes.io/name"])
+				install("Widget", "w")(ctx, t)
+			})
+
+			c := kubeclient.Get(envCtx)
+			_, err := c.CoreV1().Namespaces().Get(ctx, first, metav1.GetOptions{})
+			if tc.reuse == environment.NamespacePoolNeverReuse {
+				require.True(t, apierrors.IsNotFound(err), "namespace %s not deleted: %v", first, err)
+			} else {
+				require.NoError(t, err)
+				widgetsLeft, err := dynamicclient.Get(envCtx).Resource(widgets).Namespace(first).List(ctx, metav1.ListOptions{})
+				require.NoError(t, err)
+				require.Empty(t, widgetsLeft.Items)
+			}
+
+			if tc.reuse == environment.NamespacePoolReuseAfterScrub {
+				ns, err := c.CoreV1().Namespaces().Get(ctx, first, metav1.GetOptions{})
+				require.NoError(t, err)
+				ns.Annotations[environment.NamespaceCreatedAtAnnotation] = "2026-01-02T12:00:00Z"
+				_, err = c.CoreV1().Namespaces().Update(ctx, ns, metav1.UpdateOptions{})
+				require.NoError(t, err)
+			}
+
+			t.Run("second", func(t *testing.T) {
+				_, env := global.Environment(environment.Managed(t))
+				if tc.reuse == environment.NamespacePoolNeverReuse {
+					require.NotEqual(t, first, env.Namespace())
+				} else {
+					require.Equal(t, first, env.Namespace())
+					// The janitor counts the TTL from the reuse.
+					ns, err := c.CoreV1().Namespaces().Get(ctx, first, metav1.GetOptions{})
+					require.NoError(t, err)
+					require.NotEqual(t, "2026-01-02T12:00:00Z", ns.Annotations[environment.NamespaceCreatedAtAnnotation])
+				}
+			})
+
+			t.Run("not pooled", func(t *testing.T) {
+				_, env := global.Environment(environment.Managed(t), environment.WithNamespaceTransformFuncs(func(ns *corev1.Namespace) error {
+					ns.Labels["pooled"] = "false"
+					return nil
+				}))
+				ns, err := c.CoreV1().Namespaces().Get(ctx, env.Namespace(), metav1.GetOptions{})
+				require.NoError(t, err)
+				require.Equal(t, "false", ns.Labels["pooled"])
+			})
+
+			environment.DrainNamespacePool(global)
+			nss, err := c.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+			require.Empty(t, nss.Items)
+		})
+	}
+}
+
+func TestNamespacePoolReuseSet(t *testing.T) {
+	var reuse environment.NamespacePoolReuse
+	require.NoError(t, reuse.Set("scrub"))
+	require.Equal(t, environment.NamespacePoolReuseAfterScrub, reuse)
+	require.Equal(t, "scrub", reuse.String())
+	require.Error(t, reuse.Set("sometimes"))
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+)
+
+// Names of the objects created in the test namespace by WithNamespaceQuota,
+// WithLimitRange and WithNetworkIsolation.
+const (
+	NamespaceQuotaName   = "rekt-quota"
+	LimitRangeName       = "rekt-limit-range"
+	NetworkIsolationName = "rekt-network-isolation"
+)
+
+// WithNamespaceQuota is an environment option to create a ResourceQuota with
+// the hard limits in the test namespace, so that a runaway test can't starve
+// the other tests of a shared cluster, for example:
+//
+//	environment.WithNamespaceQuota(corev1.ResourceList{
+//		corev1.ResourcePods:        resource.MustParse("20"),
+//		corev1.ResourceLimitsMemory: resource.MustParse("4Gi"),
+//	})
+//
+// When a quota limits compute resources, pods without requests and limits are
+// rejected, unless WithLimitRange sets their defaults.
+func WithNamespaceQuota(hard corev1.ResourceList) EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		if e, ok := env.(*MagicEnvironment); ok {
+			e.namespaceQuota = hard
+		}
+		return ctx, nil
+	}
+}
+
+// WithLimitRange is an environment option to create a LimitRange with the
+// limits in the test namespace, for example to set the default requests and
+// limits of the containers.
+func WithLimitRange(limits ...corev1.LimitRangeItem) EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		if e, ok := env.(*MagicEnvironment); ok {
+			e.limitRange = limits
+		}
+		return ctx, nil
+	}
+}
+
+// WithNetworkIsolation is an environment option to create a NetworkPolicy in
+// the test namespace that only allows ingress traffic from the pods of the
+// namespace and of the namespaces allowFrom, for example knative-eventing,
+// so that tests can't reach the services of each other.
+func WithNetworkIsolation(allowFrom ...string) EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		if e, ok := env.(*MagicEnvironment); ok {
+			e.networkIsolation = true
+			e.allowFrom = allowFrom
+		}
+		return ctx, nil
+	}
+}
+
+// hasNamespaceGuards returns whether the environment creates a quota, a limit
+// range or a network policy with its namespace.
+func (mr *MagicEnvironment) hasNamespaceGuards() bool {
+	return mr.namespaceQuota != nil || len(mr.limitRange) > 0 || mr.networkIsolation
+}
+
+// createNamespaceGuards creates the quota, the limit range and the network
+// policy configured for the namespace, and references them.
+func (mr *MagicEnvironment) createNamespaceGuards() error {
+	c := kubeclient.Get(mr.c)
+	labels := map[string]string{
+		"app.kubernetes.io/component": "reconciler-test",
+		"app.kubernetes.io/name":      "reconciler-test",
+	}
+
+	if mr.namespaceQuota != nil {
+		_, err := c.CoreV1().ResourceQuotas(mr.namespace).Create(context.Background(), &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: NamespaceQuotaName, Labels: labels},
+			Spec:       corev1.ResourceQuotaSpec{Hard: mr.namespaceQuota},
+		}, metav1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create the ResourceQuota of Namespace %s: %w", mr.namespace, err)
+		}
+		mr.Reference(corev1.ObjectReference{APIVersion: "v1", Kind: "ResourceQuota", Namespace: mr.namespace, Name: NamespaceQuotaName})
+	}
+
+	if len(mr.limitRange) > 0 {
+		_, err := c.CoreV1().LimitRanges(mr.namespace).Create(context.Background(), &corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: LimitRangeName, Labels: labels},
+			Spec:       corev1.LimitRangeSpec{Limits: mr.limitRange},
+		}, metav1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create the LimitRange of Namespace %s: %w", mr.namespace, err)
+		}
+		mr.Reference(corev1.ObjectReference{APIVersion: "v1", Kind: "LimitRange", Namespace: mr.namespace, Name: LimitRangeName})
+	}
+
+	if mr.networkIsolation {
+		_, err := c.NetworkingV1().NetworkPolicies(mr.namespace).Create(context.Background(), &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: NetworkIsolationName, Labels: labels},
+			Spec:       isolationPolicy(mr.allowFrom),
+		}, metav1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create the NetworkPolicy of Namespace %s: %w", mr.namespace, err)
+		}
+		mr.Reference(corev1.ObjectReference{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy", Namespace: mr.namespace, Name: NetworkIsolationName})
+	}
+
+	return nil
+}
+
+// isolationPolicy allows the ingress traffic from the pods of the namespace
+// and from the namespaces allowFrom, egress is not restricted.
+func isolationPolicy(allowFrom []string) networkingv1.NetworkPolicySpec {
+	peers := []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}
+	if len(allowFrom) > 0 {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      corev1.LabelMetadataName,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   allowFrom,
+				}},
+			},
+		})
+	}
+	return networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		Ingress:     []networkingv1.NetworkPolicyIngressRule{{From: peers}},
+	}
+}
+
+// IsQuotaExceeded returns whether err is the rejection of a resource by a
+// ResourceQuota.
+func IsQuotaExceeded(err error) bool {
+	return apierrors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota")
+}
+
+// DescribeQuotaExceeded returns err with the usage of the quotas of the test
+// namespace, when err is the rejection of a resource by a ResourceQuota, and
+// err otherwise.
+func DescribeQuotaExceeded(ctx context.Context, err error) error {
+	if !IsQuotaExceeded(err) {
+		return err
+	}
+	env, ok := ctx.Value(envKey{}).(*MagicEnvironment)
+	if !ok {
+		return err
+	}
+	quotas, lerr := kubeclient.Get(ctx).CoreV1().ResourceQuotas(env.namespace).List(ctx, metav1.ListOptions{})
+	if lerr != nil || len(quotas.Items) == 0 {
+		return fmt.Errorf("namespace %s ran out of quota: %w", env.namespace, err)
+	}
+
+	var usage []string
+	for _, q := range quotas.Items {
+		for name, hard := range q.Spec.Hard {
+			used := q.Status.Used[name]
+			usage = append(usage, fmt.Sprintf("%s %s %s/%s", q.Name, name, used.String(), hard.String()))
+		}
+	}
+	sort.Strings(usage)
+	return fmt.Errorf("namespace %s ran out of quota (%s), raise it with environment.WithNamespaceQuota: %w",
+		env.namespace, strings.Join(usage, ", "), err)
+}
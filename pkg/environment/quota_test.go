@@ -0,0 +1,111 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/environment/fakeenv"
+	testlog "knative.dev/reconciler-test/pkg/logging"
+)
+
+func TestNamespaceGuards(t *testing.T) {
+	global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t),
+		fakeenv.WithNamespacePool(1, environment.NamespacePoolNeverReuse),
+	)
+	ctx, env := global.Environment(environment.Managed(t),
+		environment.WithNamespaceQuota(corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2")}),
+		environment.WithLimitRange(corev1.LimitRangeItem{
+			Type:    corev1.LimitTypeContainer,
+			Default: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+		}),
+		environment.WithNetworkIsolation("knative-eventing"),
+	)
+	t.Cleanup(func() { environment.DrainNamespacePool(global) })
+	ns := env.Namespace()
+	c := kubeclient.Get(ctx)
+
+	quota, err := c.CoreV1().ResourceQuotas(ns).Get(ctx, environment.NamespaceQuotaName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "2", quota.Spec.Hard.Pods().String())
+
+	limits, err := c.CoreV1().LimitRanges(ns).Get(ctx, environment.LimitRangeName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, limits.Spec.Limits, 1)
+
+	policy, err := c.NetworkingV1().NetworkPolicies(ns).Get(ctx, environment.NetworkIsolationName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, policy.Spec.Ingress, 1)
+	from := policy.Spec.Ingress[0].From
+	require.Len(t, from, 2)
+	require.Equal(t, []string{"knative-eventing"}, from[1].NamespaceSelector.MatchExpressions[0].Values)
+
+	require.ElementsMatch(t, []corev1.ObjectReference{
+		{APIVersion: "v1", Kind: "ResourceQuota", Namespace: ns, Name: environment.NamespaceQuotaName},
+		{APIVersion: "v1", Kind: "LimitRange", Namespace: ns, Name: environment.LimitRangeName},
+		{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy", Namespace: ns, Name: environment.NetworkIsolationName},
+	}, env.References())
+
+	// The namespaces of the pool don't have the guards.
+	pooled, err := c.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	for _, p := range pooled.Items {
+		if p.Name != ns {
+			_, err := c.CoreV1().ResourceQuotas(p.Name).Get(ctx, environment.NamespaceQuotaName, metav1.GetOptions{})
+			require.True(t, apierrors.IsNotFound(err))
+		}
+	}
+
+	quota.Status.Used = corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2")}
+	_, err = c.CoreV1().ResourceQuotas(ns).UpdateStatus(ctx, quota, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	exceeded := apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "p",
+		errors.New("exceeded quota: rekt-quota, requested: pods=1, used: pods=2, limited: pods=2"))
+	err = environment.DescribeQuotaExceeded(ctx, fmt.Errorf("failed to create resource %w", exceeded))
+	require.True(t, environment.IsQuotaExceeded(err))
+	require.ErrorContains(t, err, fmt.Sprintf("namespace %s ran out of quota (rekt-quota pods 2/2)", ns))
+
+	other := apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "p", errors.New("denied by policy"))
+	require.Equal(t, other, environment.DescribeQuotaExceeded(ctx, other))
+}
+
+func TestNamespaceGuardsExistingNamespace(t *testing.T) {
+	global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t),
+		fakeenv.WithKubeObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "existing"}}),
+	)
+	ctx, env := global.Environment(environment.Managed(t),
+		environment.WithNamespace("existing"),
+		environment.WithNamespaceQuota(corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2")}),
+	)
+
+	_, err := kubeclient.Get(ctx).CoreV1().ResourceQuotas("existing").Get(ctx, environment.NamespaceQuotaName, metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "the existing namespace is left as is: %v", err)
+	require.Empty(t, env.References())
+}
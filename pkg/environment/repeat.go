@@ -0,0 +1,269 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/logging"
+
+	"knative.dev/reconciler-test/pkg/feature"
+)
+
+// repeatDir is the directory, relative to $ARTIFACTS, where the reports of
+// the repeated features are written.
+const repeatDir = "rekt-repeat"
+
+// WithRepeat is an environment option to test each feature n times, as set by
+// the feature.repeat flag, with a fresh state.Store for each run, to measure
+// how flaky the features are. A State set on the feature by the caller is
+// shared by the runs instead. The runs share the resource names chosen when
+// the feature was built, the resources referenced by a run are deleted before
+// the next one, unless WithRepeatInSeparateNamespaces gives each run its own
+// namespace.
+func WithRepeat(n int) EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		if e, ok := env.(*MagicEnvironment); ok {
+			e.repeat = n
+		}
+		return ctx, nil
+	}
+}
+
+// WithRepeatUntilFail is an environment option to repeat each feature until
+// it fails, as set by the feature.repeat.until-fail flag, at most the number
+// of times set with WithRepeat when above 1.
+func WithRepeatUntilFail() EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		if e, ok := env.(*MagicEnvironment); ok {
+			e.repeatUntilFail = true
+		}
+		return ctx, nil
+	}
+}
+
+// WithRepeatInSeparateNamespaces is an environment option to test each run of
+// a repeated feature in its own namespace, as set by the
+// feature.repeat.separate-namespaces flag, which is deleted when the run
+// passed.
+func WithRepeatInSeparateNamespaces() EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		if e, ok := env.(*MagicEnvironment); ok {
+			e.repeatNamespaces = true
+		}
+		return ctx, nil
+	}
+}
+
+// repeatReport is the pass rate of a repeated feature, with the number of
+// failures of each step keyed by timing and step name.
+type repeatReport struct {
+	Feature      string         `json:"feature"`
+	Runs         int            `json:"runs"`
+	Passed       int            `json:"passed"`
+	PassRate     float64        `json:"passRate"`
+	StepFailures map[string]int `json:"stepFailures"`
+
+	mu sync.Mutex
+}
+
+// addFailed records the failed steps of a timing.
+func (r *repeatReport) addFailed(timing feature.Timing, steps []*feature.Step) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range steps {
+		r.StepFailures[timing.String()+"/"+s.Name]++
+	}
+}
+
+func (r *repeatReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Feature %q passed %d/%d runs (%.1f%%)", r.Feature, r.Passed, r.Runs, 100*r.PassRate)
+	steps := make([]string, 0, len(r.StepFailures))
+	for s := range r.StepFailures {
+		steps = append(steps, s)
+	}
+	sort.Slice(steps, func(i, j int) bool {
+		if r.StepFailures[steps[i]] != r.StepFailures[steps[j]] {
+			return r.StepFailures[steps[i]] > r.StepFailures[steps[j]]
+		}
+		return steps[i] < steps[j]
+	})
+	for _, s := range steps {
+		fmt.Fprintf(&sb, "\n  %3d %s %s", r.StepFailures[s], strings.Repeat("#", r.StepFailures[s]), s)
+	}
+	return sb.String()
+}
+
+type repeatReportKey struct{}
+
+// recordFailedSteps records the failed steps of a timing in the report of the
+// repeated feature being tested, if any.
+func recordFailedSteps(ctx context.Context, timing feature.Timing, steps []*feature.Step) {
+	if r, ok := ctx.Value(repeatReportKey{}).(*repeatReport); ok && len(steps) > 0 {
+		r.addFailed(timing, steps)
+	}
+}
+
+// repeated returns whether the features are tested more than once.
+func (mr *MagicEnvironment) repeated() bool {
+	return mr.repeat > 1 || mr.repeatUntilFail
+}
+
+// repeatFeature tests a clone of the feature in a sub test for each run, one
+// run after the other, with the State set by the caller if any, then writes the report to
+// $ARTIFACTS/rekt-repeat/<feature>.json. The report is logged at Finish.
+func (mr *MagicEnvironment) repeatFeature(ctx context.Context, originalT *testing.T, f *feature.Feature) {
+	originalT.Helper()
+
+	limit := mr.repeat
+	if mr.repeatUntilFail && limit <= 1 {
+		limit = 0 // No limit.
+	}
+	report := &repeatReport{Feature: f.Name, StepFailures: make(map[string]int)}
+	// Runs don't run in parallel to each other, so that they can stop at the
+	// first failure.
+	ctx = context.WithValue(ctx, parallelKey{}, false)
+	ctx = context.WithValue(ctx, repeatReportKey{}, report)
+
+	refs := 0
+	for run := 1; limit == 0 || run <= limit; run++ {
+		if run > 1 && !mr.repeatNamespaces {
+			// The runs share the namespace and the names chosen when the
+			// feature was built, the resources of the previous run have to
+			// be gone.
+			if previous := mr.takeReferences(refs); len(previous) > 0 {
+				if err := feature.DeleteResources(mr.c, originalT, previous); err != nil {
+					originalT.Error(err)
+					break
+				}
+			}
+		}
+		refs = len(mr.References())
+		passed := originalT.Run(fmt.Sprintf("run-%d", run), func(t *testing.T) {
+			runCtx, env := ctx, mr
+			if mr.repeatNamespaces {
+				runCtx, env = mr.runEnvironment(ctx, t)
+				defer env.finishRun(t)
+			}
+			clone := f.Clone()
+			// A store set by the caller, like the one shared by the phases of
+			// an upgrade, is kept, otherwise each run gets its own.
+			clone.State = f.State
+			env.test(runCtx, t, clone)
+		})
+		report.Runs++
+		if passed {
+			report.Passed++
+		} else if mr.repeatUntilFail {
+			break
+		}
+	}
+	report.PassRate = float64(report.Passed) / float64(report.Runs)
+
+	if err := writeRepeatReport(report); err != nil {
+		originalT.Log("Failed to write the repeat report: ", err)
+	}
+	mr.repeatReportsMu.Lock()
+	mr.repeatReports = append(mr.repeatReports, report)
+	mr.repeatReportsMu.Unlock()
+}
+
+// runEnvironment returns an environment like mr in a new namespace, for a
+// single run of a repeated feature.
+func (mr *MagicEnvironment) runEnvironment(ctx context.Context, t *testing.T) (context.Context, *MagicEnvironment) {
+	env := &MagicEnvironment{
+		environmentSettings: mr.environmentSettings,
+		namespace:           mr.rand.MakeRandomK8sName("test"),
+	}
+	ctx = ContextWith(ctx, env)
+	env.c = ContextWith(mr.c, env)
+	if err := env.CreateNamespaceIfNeeded(); err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range GetPostInit(ctx) {
+		var err error
+		if ctx, err = in(ctx, env); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ctx, env
+}
+
+// takeReferences removes the references added since the environment had n
+// references and returns them.
+func (mr *MagicEnvironment) takeReferences(n int) []corev1.ObjectReference {
+	mr.refsMu.Lock()
+	defer mr.refsMu.Unlock()
+
+	if n > len(mr.refs) {
+		return nil
+	}
+	refs := mr.refs[n:]
+	mr.refs = mr.refs[:n:n]
+	return refs
+}
+
+// finishRun deletes the resources and the namespace of a run environment
+// when the run passed.
+func (mr *MagicEnvironment) finishRun(t *testing.T) {
+	if !t.Failed() {
+		if err := feature.DeleteResources(mr.c, t, mr.References()); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	if err := mr.DeleteNamespaceIfNeeded(t); err != nil {
+		t.Error(err)
+	}
+}
+
+// logRepeatReports logs the reports of the repeated features.
+func (mr *MagicEnvironment) logRepeatReports() {
+	mr.repeatReportsMu.Lock()
+	defer mr.repeatReportsMu.Unlock()
+
+	for _, r := range mr.repeatReports {
+		if mr.managedT != nil {
+			mr.managedT.Log(r.String())
+		} else {
+			logging.FromContext(mr.c).Info(r.String())
+		}
+	}
+}
+
+func writeRepeatReport(r *repeatReport) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(artifactsDir(), repeatDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, feature.MakeK8sNamePrefix(r.Feature)+".json"), b, 0644)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/environment/fakeenv"
+	"knative.dev/reconciler-test/pkg/feature"
+	testlog "knative.dev/reconciler-test/pkg/logging"
+)
+
+func TestRepeatFeatureInSeparateNamespaces(t *testing.T) {
+	t.Setenv("ARTIFACTS", t.TempDir())
+	global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t))
+	ctx, env := global.Environment(environment.WithRepeat(2), environment.WithRepeatInSeparateNamespaces())
+	defer env.Finish()
+
+	var namespaces []string
+	f := feature.NewFeatureNamed("Namespaced")
+	f.Setup("record", func(ctx context.Context, t feature.T) {
+		namespaces = append(namespaces, environment.FromContext(ctx).Namespace())
+	})
+	env.Test(ctx, t, f)
+
+	require.Len(t, namespaces, 2)
+	require.NotEqual(t, namespaces[0], namespaces[1])
+	for _, ns := range namespaces {
+		require.NotEqual(t, env.Namespace(), ns)
+		_, err := kubeclient.Get(ctx).CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+		require.True(t, apierrors.IsNotFound(err), "namespace %s is deleted once the run passed", ns)
+	}
+}
+
+func TestRepeatFeatureInSharedNamespace(t *testing.T) {
+	t.Setenv("ARTIFACTS", t.TempDir())
+	global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t))
+	ctx, env := global.Environment(environment.WithRepeat(2))
+	defer env.Finish()
+
+	// Both runs create the same widget, the first one has to be deleted.
+	f := feature.NewFeatureNamed("Shared")
+	f.Setup("install widget", install("Widget", "w"))
+	env.Test(ctx, t, f)
+
+	require.Len(t, env.References(), 1)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/milestone"
+	"knative.dev/reconciler-test/pkg/state"
+)
+
+const repeatHelperEnv = "REKT_TEST_REPEAT_UNTIL_FAIL"
+
+// TestRepeatFeatureHelper repeats a feature failing every other run. It fails
+// by design and only runs as a sub process of TestRepeatFeature.
+func TestRepeatFeatureHelper(t *testing.T) {
+	untilFail, err := strconv.ParseBool(os.Getenv(repeatHelperEnv))
+	if err != nil {
+		t.Skip("Only runs as a sub process of TestRepeatFeature")
+	}
+
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:               feature.All,
+			s:               feature.Any,
+			featureMatch:    regexp.MustCompile(""),
+			milestones:      milestone.Compose(),
+			stateStore:      newKVStore,
+			repeat:          4,
+			repeatUntilFail: untilFail,
+		},
+	}
+
+	runs := 0
+	f := feature.NewFeatureNamed("Flaky")
+	f.Setup("create", func(ctx context.Context, t feature.T) {
+		runs++
+		if _, err := state.Get[int](ctx, "run"); err == nil {
+			t.Error("State of the previous run")
+		}
+		state.SetOrFail(ctx, t, "run", runs)
+	})
+	f.Assert("flaky", func(ctx context.Context, t feature.T) {
+		if state.MustGet[int](ctx, t, "run")%2 == 0 {
+			t.Error("Failing on even runs")
+		}
+	})
+	mr.runFeature(context.Background(), t, f)
+}
+
+func TestRepeatFeature(t *testing.T) {
+	tests := map[string]struct {
+		untilFail bool
+		want      *repeatReport
+	}{
+		"repeat": {
+			want: &repeatReport{
+				Feature:      "Flaky",
+				Runs:         4,
+				Passed:       2,
+				PassRate:     0.5,
+				StepFailures: map[string]int{"Assert/flaky": 2},
+			},
+		},
+		"until fail": {
+			untilFail: true,
+			want: &repeatReport{
+				Feature:      "Flaky",
+				Runs:         2,
+				Passed:       1,
+				PassRate:     0.5,
+				StepFailures: map[string]int{"Assert/flaky": 1},
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			artifacts := t.TempDir()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestRepeatFeatureHelper$")
+			cmd.Env = append(os.Environ(), "ARTIFACTS="+artifacts, repeatHelperEnv+"="+strconv.FormatBool(tc.untilFail))
+			out, err := cmd.CombinedOutput()
+			require.Error(t, err, "the helper test fails:\n%s", out)
+
+			b, err := os.ReadFile(filepath.Join(artifacts, repeatDir, "flaky.json"))
+			require.NoError(t, err, string(out))
+			var got repeatReport
+			require.NoError(t, json.Unmarshal(b, &got))
+			require.Equal(t, tc.want.Feature, got.Feature)
+			require.Equal(t, tc.want.Runs, got.Runs)
+			require.Equal(t, tc.want.Passed, got.Passed)
+			require.Equal(t, tc.want.PassRate, got.PassRate)
+			require.Equal(t, tc.want.StepFailures, got.StepFailures)
+		})
+	}
+}
+
+func TestRepeatFeatureCallerState(t *testing.T) {
+	t.Setenv("ARTIFACTS", t.TempDir())
+	mr := &MagicEnvironment{
+		environmentSettings: environmentSettings{
+			l:            feature.All,
+			s:            feature.Any,
+			featureMatch: regexp.MustCompile(""),
+			milestones:   milestone.Compose(),
+			stateStore:   newKVStore,
+			repeat:       3,
+		},
+	}
+
+	store := &state.KVStore{}
+	require.NoError(t, store.Set(context.Background(), "runs", 0))
+	f := feature.NewFeatureNamed("Stateful")
+	f.State = store
+	f.Setup("count", func(ctx context.Context, t feature.T) {
+		state.SetOrFail(ctx, t, "runs", state.MustGet[int](ctx, t, "runs")+1)
+	})
+	mr.runFeature(context.Background(), t, f)
+
+	require.Same(t, store, f.State)
+	var runs int
+	require.NoError(t, store.Get(context.Background(), "runs", &runs))
+	require.Equal(t, 3, runs)
+}
+
+func TestRepeatReportString(t *testing.T) {
+	r := &repeatReport{
+		Feature:      "Flaky",
+		Runs:         10,
+		Passed:       7,
+		PassRate:     0.7,
+		StepFailures: map[string]int{"Setup/install": 1, "Assert/flaky": 3},
+	}
+	require.Equal(t, `Feature "Flaky" passed 7/10 runs (70.0%)
+    3 ### Assert/flaky
+    1 # Setup/install`, r.String())
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"knative.dev/pkg/logging"
+)
+
+// timingsDir is the directory, relative to $ARTIFACTS, where the durations of
+// the features are written, one file per global environment.
+const timingsDir = "rekt-timings"
+
+// Shard selects the features tested by one of Count CI jobs, Index being
+// 1-based. Each feature is assigned to a shard by a stable hash of its full
+// name, or, with WithTimings, so that shards take similar time. The zero Shard
+// selects every feature.
+type Shard struct {
+	Index int
+	Count int
+
+	// assignment is the shard of the features with a known duration.
+	assignment map[string]int
+}
+
+// ParseShard parses a shard formatted as "i/n", for example "2/4".
+func ParseShard(s string) (Shard, error) {
+	var shard Shard
+	return shard, shard.Set(s)
+}
+
+// String implements flag.Value.
+func (s *Shard) String() string {
+	if s == nil || s.Count == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// Set implements flag.Value.
+func (s *Shard) Set(v string) error {
+	if v == "" {
+		*s = Shard{}
+		return nil
+	}
+	i, n, ok := strings.Cut(v, "/")
+	if !ok {
+		return fmt.Errorf("invalid shard %q, expected i/n", v)
+	}
+	index, err := strconv.Atoi(i)
+	if err != nil {
+		return fmt.Errorf("invalid shard %q: %w", v, err)
+	}
+	count, err := strconv.Atoi(n)
+	if err != nil {
+		return fmt.Errorf("invalid shard %q: %w", v, err)
+	}
+	if count < 1 || index < 1 || index > count {
+		return fmt.Errorf("invalid shard %q, expected 1 <= i <= n", v)
+	}
+	*s = Shard{Index: index, Count: count}
+	return nil
+}
+
+// WithTimings returns the shard with the features of timings assigned so that
+// the shards take similar time: the longest features first, each to the
+// shard with the least total duration so far. The other features are
+// assigned by hash.
+func (s Shard) WithTimings(timings map[string]time.Duration) Shard {
+	if s.Count == 0 {
+		return s
+	}
+	names := make([]string, 0, len(timings))
+	for name := range timings {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if timings[names[i]] != timings[names[j]] {
+			return timings[names[i]] > timings[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	totals := make([]time.Duration, s.Count)
+	s.assignment = make(map[string]int, len(names))
+	for _, name := range names {
+		shard := 0
+		for i := range totals {
+			if totals[i] < totals[shard] {
+				shard = i
+			}
+		}
+		totals[shard] += timings[name]
+		s.assignment[name] = shard + 1
+	}
+	return s
+}
+
+// Of returns the 1-based shard of the feature with the given full name.
+func (s Shard) Of(name string) int {
+	if s.Count == 0 {
+		return 0
+	}
+	if shard, ok := s.assignment[name]; ok {
+		return shard
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int(h.Sum64()%uint64(s.Count)) + 1
+}
+
+// Includes returns whether the feature with the given full name belongs to
+// the shard.
+func (s Shard) Includes(name string) bool {
+	return s.Count == 0 || s.Of(name) == s.Index
+}
+
+// WithShard is an environment option to test only the features of the shard,
+// as set by the rekt.shard flag, the other features are skipped.
+func WithShard(s Shard) EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		if e, ok := env.(*MagicEnvironment); ok {
+			e.shard = s
+		}
+		return ctx, nil
+	}
+}
+
+// LoadFeatureTimings reads the durations of the features written to
+// $ARTIFACTS/rekt-timings/ by previous runs. The path is either one of those
+// files or a directory, in which case all of its JSON files are merged, for
+// example the files of every shard of a previous CI run.
+func LoadFeatureTimings(path string) (map[string]time.Duration, error) {
+	files := []string{path}
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+			return nil, err
+		}
+	}
+
+	timings := make(map[string]time.Duration)
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		seconds := make(map[string]float64)
+		if err := json.Unmarshal(b, &seconds); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %w", file, err)
+		}
+		for name, s := range seconds {
+			timings[name] = time.Duration(s * float64(time.Second))
+		}
+	}
+	return timings, nil
+}
+
+// loadShard returns the shard weighted with the timings file, if any, or
+// assigned by hash only when the file can't be loaded.
+func loadShard(ctx context.Context, s Shard, timingsPath string) Shard {
+	if s.Count == 0 || timingsPath == "" {
+		return s
+	}
+	timings, err := LoadFeatureTimings(timingsPath)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("Failed to load the feature timings, assigning the features to shards by hash: %v", err)
+		return s
+	}
+	return s.WithTimings(timings)
+}
+
+// featureTimings records the durations of the features tested by the
+// environments of a global environment.
+type featureTimings struct {
+	// path is the file where the durations are written, in seconds.
+	path string
+
+	mu      sync.Mutex
+	timings map[string]time.Duration
+}
+
+// newFeatureTimings returns the featureTimings of a global environment, only
+// when the durations are kept as artifacts or sharding is requested, nil
+// otherwise.
+func newFeatureTimings(instanceID string, shard Shard, timingsPath string) *featureTimings {
+	if os.Getenv("ARTIFACTS") == "" && shard.Count == 0 && timingsPath == "" {
+		return nil
+	}
+	return &featureTimings{
+		path:    filepath.Join(artifactsDir(), timingsDir, instanceID+".json"),
+		timings: make(map[string]time.Duration),
+	}
+}
+
+func (ft *featureTimings) record(name string, d time.Duration) {
+	if ft == nil {
+		return
+	}
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	ft.timings[name] = d
+}
+
+// write writes the durations of all the features tested so far.
+func (ft *featureTimings) write() error {
+	if ft == nil {
+		return nil
+	}
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	if len(ft.timings) == 0 {
+		return nil
+	}
+	seconds := make(map[string]float64, len(ft.timings))
+	for name, d := range ft.timings {
+		seconds[name] = d.Seconds()
+	}
+	b, err := json.MarshalIndent(seconds, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ft.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(ft.path, b, 0644)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/environment/fakeenv"
+	"knative.dev/reconciler-test/pkg/feature"
+	testlog "knative.dev/reconciler-test/pkg/logging"
+)
+
+func TestParseShard(t *testing.T) {
+	s, err := environment.ParseShard("2/4")
+	require.NoError(t, err)
+	require.Equal(t, 2, s.Index)
+	require.Equal(t, 4, s.Count)
+	require.Equal(t, "2/4", s.String())
+
+	for _, invalid := range []string{"2", "0/4", "5/4", "1/0", "a/4", "1/b"} {
+		_, err := environment.ParseShard(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestShardIncludes(t *testing.T) {
+	var names []string
+	for i := 0; i < 100; i++ {
+		names = append(names, fmt.Sprintf("Set/Feature-%d", i))
+	}
+
+	counts := make(map[string]int)
+	for i := 1; i <= 3; i++ {
+		s := environment.Shard{Index: i, Count: 3}
+		for _, name := range names {
+			if s.Includes(name) {
+				counts[name]++
+				require.Equal(t, i, s.Of(name))
+			}
+		}
+	}
+	for _, name := range names {
+		require.Equal(t, 1, counts[name], "%s is in exactly one shard", name)
+	}
+
+	// The assignment is stable across runs.
+	require.Equal(t, 1, environment.Shard{Index: 1, Count: 4}.Of("Set/Feature-1"))
+	require.True(t, environment.Shard{}.Includes("Set/Feature-1"))
+}
+
+func TestShardWithTimings(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1.json"), []byte(`{"a": 60, "b": 30}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2.json"), []byte(`{"c": 20, "d": 10, "e": 0.5}`), 0644))
+
+	timings, err := environment.LoadFeatureTimings(dir)
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Duration{
+		"a": time.Minute,
+		"b": 30 * time.Second,
+		"c": 20 * time.Second,
+		"d": 10 * time.Second,
+		"e": 500 * time.Millisecond,
+	}, timings)
+
+	s := environment.Shard{Index: 1, Count: 2}.WithTimings(timings)
+	shards := make(map[int][]string)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		shards[s.Of(name)] = append(shards[s.Of(name)], name)
+	}
+	require.Equal(t, map[int][]string{
+		1: {"a", "e"},
+		2: {"b", "c", "d"},
+	}, shards)
+}
+
+func TestShardSkipsFeatures(t *testing.T) {
+	global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t))
+
+	ran := make(map[string]int)
+	for i := 1; i <= 2; i++ {
+		t.Run(fmt.Sprintf("shard-%d", i), func(t *testing.T) {
+			ctx, env := global.Environment(environment.WithShard(environment.Shard{Index: i, Count: 2}))
+			defer env.Finish()
+
+			fs := &feature.FeatureSet{Name: "Set"}
+			for j := 0; j < 10; j++ {
+				f := feature.NewFeatureNamed(fmt.Sprintf("Feature-%d", j))
+				f.Setup("run", func(ctx context.Context, t feature.T) {
+					ran[f.Name]++
+				})
+				fs.Features = append(fs.Features, f)
+			}
+			env.TestSet(ctx, t, fs)
+		})
+	}
+
+	require.Len(t, ran, 10)
+	for name, n := range ran {
+		require.Equal(t, 1, n, name)
+	}
+}
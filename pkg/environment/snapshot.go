@@ -0,0 +1,266 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+	"knative.dev/pkg/ptr"
+	"sigs.k8s.io/yaml"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/internal/k8sevents"
+)
+
+const (
+	// snapshotLogLines and snapshotLogBytes cap the logs of each container in
+	// a snapshot, keeping the most recent ones.
+	snapshotLogLines = 5000
+	snapshotLogBytes = 1 << 20
+)
+
+// WithFailureSnapshot is an environment option to override whether a snapshot
+// of the test namespace is written when a feature fails, as set by the
+// rekt.snapshot-on-fail flag.
+func WithFailureSnapshot(enabled bool) EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		if e, ok := env.(*MagicEnvironment); ok {
+			e.snapshotOnFail = enabled
+		}
+		return ctx, nil
+	}
+}
+
+// snapshotFile is a file of a namespace snapshot.
+type snapshotFile struct {
+	name    string
+	content []byte
+}
+
+// SnapshotNamespace writes a snapshot of the test namespace to
+// $ARTIFACTS/<feature>/snapshot-<namespace>-<time>.tar.gz, or to the temporary
+// directory when ARTIFACTS isn't set. The snapshot holds every resource of the
+// namespace as YAML, with the data of Secrets redacted, the Events, the last
+// logs of every container including the previous ones, the content of the state.Store
+// of the feature and an index.html linking them.
+//
+// It runs as a Teardown step when a feature fails, unless disabled with
+// WithFailureSnapshot or the rekt.snapshot-on-fail flag.
+func SnapshotNamespace(ctx context.Context, t feature.T) {
+	namespace := FromContext(ctx).Namespace()
+
+	var files []snapshotFile
+	var errs []string
+	add := func(name string, content []byte) {
+		files = append(files, snapshotFile{name: name, content: content})
+	}
+	addYAML := func(name string, obj interface{}) {
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			return
+		}
+		add(name, b)
+	}
+
+	resources, err := listableResources(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("discovery: %v", err))
+	}
+	for _, r := range resources {
+		if !r.namespaced || ignoredResources.Has(r.GroupResource()) {
+			continue
+		}
+		l, err := dynamicclient.Get(ctx).Resource(r.GroupVersionResource).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			if !ignoreListError(err) {
+				errs = append(errs, fmt.Sprintf("%s: %v", r.GroupVersionResource, err))
+			}
+			continue
+		}
+		dir := r.Resource
+		if r.Group != "" {
+			dir += "." + r.Group
+		}
+		for i := range l.Items {
+			obj := &l.Items[i]
+			if r.Group == "" && r.Resource == "secrets" {
+				redactSecret(obj)
+			}
+			addYAML(path.Join("resources", dir, obj.GetName()+".yaml"), obj.Object)
+		}
+	}
+
+	kube := kubeclient.Get(ctx)
+	if events, err := kube.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		errs = append(errs, fmt.Sprintf("events: %v", err))
+	} else {
+		addYAML("events.yaml", k8sevents.SortByTime(events.Items))
+	}
+
+	if pods, err := kube.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		errs = append(errs, fmt.Sprintf("pods: %v", err))
+	} else {
+		for _, pod := range pods.Items {
+			restarted := make(map[string]bool)
+			for _, s := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+				restarted[s.Name] = s.RestartCount > 0
+			}
+			for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+				for _, previous := range []bool{false, true} {
+					if previous && !restarted[c.Name] {
+						continue
+					}
+					name := path.Join("logs", pod.Name, c.Name+".log")
+					if previous {
+						name = path.Join("logs", pod.Name, c.Name+".previous.log")
+					}
+					logs, err := kube.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+						Container:  c.Name,
+						Previous:   previous,
+						TailLines:  ptr.Int64(snapshotLogLines),
+						LimitBytes: ptr.Int64(snapshotLogBytes),
+					}).DoRaw(ctx)
+					if err != nil {
+						errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+						continue
+					}
+					add(name, logs)
+				}
+			}
+		}
+	}
+
+	if f := feature.FromContext(ctx); f != nil && f.State != nil {
+		if b, err := json.MarshalIndent(f.State, "", "  "); err != nil {
+			errs = append(errs, fmt.Sprintf("state: %v", err))
+		} else {
+			add("state.json", b)
+		}
+	}
+
+	if len(errs) > 0 {
+		add("errors.txt", []byte(strings.Join(errs, "\n")+"\n"))
+	}
+
+	name := ""
+	if f := feature.FromContext(ctx); f != nil {
+		name = f.Name
+	}
+	file, err := writeSnapshot(name, namespace, files)
+	if err != nil {
+		t.Log("Failed to write the namespace snapshot: ", err)
+		return
+	}
+	t.Logf("Namespace snapshot written to %s", file)
+}
+
+// redactSecret replaces the values of a Secret, which may hold the
+// credentials of the image pull Secret of the namespace.
+func redactSecret(obj *unstructured.Unstructured) {
+	for _, field := range []string{"data", "stringData"} {
+		data, ok, _ := unstructured.NestedMap(obj.Object, field)
+		if !ok {
+			continue
+		}
+		for k := range data {
+			data[k] = "<redacted>"
+		}
+		_ = unstructured.SetNestedMap(obj.Object, data, field)
+	}
+	annotations := obj.GetAnnotations()
+	delete(annotations, corev1.LastAppliedConfigAnnotation)
+	obj.SetAnnotations(annotations)
+}
+
+// writeSnapshot writes the files, and an index.html linking them, as a
+// gzipped tarball in the artifacts directory of the feature.
+func writeSnapshot(featureName, namespace string, files []snapshotFile) (string, error) {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].name < files[j].name
+	})
+	files = append([]snapshotFile{{name: "index.html", content: snapshotIndex(featureName, namespace, files)}}, files...)
+
+	dir := artifactsDir()
+	if featureName != "" {
+		dir = filepath.Join(dir, feature.MakeK8sNamePrefix(featureName))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	name := filepath.Join(dir, fmt.Sprintf("snapshot-%s-%s.tar.gz", namespace, now.Format("20060102T150405")))
+	out, err := os.Create(name)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    f.name,
+			Mode:    0644,
+			Size:    int64(len(f.content)),
+			ModTime: now,
+		}); err != nil {
+			return "", err
+		}
+		if _, err := tw.Write(f.content); err != nil {
+			return "", err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+	if err := gz.Close(); err != nil {
+		return "", err
+	}
+	return name, out.Close()
+}
+
+func snapshotIndex(featureName, namespace string, files []snapshotFile) []byte {
+	var sb strings.Builder
+	title := html.EscapeString(fmt.Sprintf("Namespace %s", namespace))
+	if featureName != "" {
+		title = html.EscapeString(fmt.Sprintf("%s, namespace %s", featureName, namespace))
+	}
+	fmt.Fprintf(&sb, "<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"><title>%s</title></head>\n<body>\n<h1>%s</h1>\n<ul>\n", title, title)
+	for _, f := range files {
+		name := html.EscapeString(f.name)
+		fmt.Fprintf(&sb, "<li><a href=\"%s\">%s</a> (%d bytes)</li>\n", name, name, len(f.content))
+	}
+	sb.WriteString("</ul>\n</body>\n</html>\n")
+	return []byte(sb.String())
+}
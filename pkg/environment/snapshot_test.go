@@ -0,0 +1,131 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/environment/fakeenv"
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/feature/featuretest"
+	testlog "knative.dev/reconciler-test/pkg/logging"
+	"knative.dev/reconciler-test/pkg/state"
+)
+
+func TestSnapshotNamespace(t *testing.T) {
+	artifacts := t.TempDir()
+	t.Setenv("ARTIFACTS", artifacts)
+
+	global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t),
+		fakeenv.WithListKinds(map[schema.GroupVersionResource]string{widgets: "WidgetList"}),
+	)
+	ctx, env := global.Environment()
+	defer env.Finish()
+	ns := env.Namespace()
+
+	kube := kubeclient.Get(ctx)
+	kube.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "example.dev/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "widgets", Namespaced: true, Kind: "Widget", Verbs: []string{"list"}},
+		},
+	}}
+	_, err := kube.CoreV1().Pods(ns).Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "receiver"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init"}},
+			Containers:     []corev1.Container{{Name: "user-container"}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "user-container", RestartCount: 2}},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = kube.CoreV1().Events(ns).Create(ctx, &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "receiver.1"},
+		Reason:     "BackOff",
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	f := feature.NewFeature()
+	f.State = &state.KVStore{}
+	require.NoError(t, f.State.Set(ctx, "sink", "http://receiver"))
+	ctx = feature.ContextWith(ctx, f)
+
+	featuretest.New("install").Run(ctx, install("Widget", "w")).AssertPassed(t)
+	ft := featuretest.New("snapshot").Run(ctx, environment.SnapshotNamespace)
+	ft.AssertPassed(t)
+	ft.AssertLogged(t, "Namespace snapshot written to ")
+
+	matches, err := filepath.Glob(filepath.Join(artifacts, feature.MakeK8sNamePrefix(f.Name), "snapshot-"+ns+"-*.tar.gz"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+
+	file, err := os.Open(matches[0])
+	require.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	files := make(map[string]string)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		b, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(b)
+	}
+
+	require.ElementsMatch(t, []string{
+		"index.html",
+		"events.yaml",
+		"logs/receiver/init.log",
+		"logs/receiver/user-container.log",
+		"logs/receiver/user-container.previous.log",
+		"resources/widgets.example.dev/w.yaml",
+		"state.json",
+	}, keys(files))
+	require.Contains(t, files["index.html"], `<a href="resources/widgets.example.dev/w.yaml">`)
+	require.Contains(t, files["events.yaml"], "reason: BackOff")
+	require.Contains(t, files["resources/widgets.example.dev/w.yaml"], "kind: Widget")
+	require.JSONEq(t, `{"sink": "\"http://receiver\""}`, files["state.json"])
+}
+
+func keys(m map[string]string) []string {
+	r := make([]string, 0, len(m))
+	for k := range m {
+		r = append(r, k)
+	}
+	return r
+}
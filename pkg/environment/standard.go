@@ -74,6 +74,12 @@ func NewStandardGlobalEnvironmentWithRestConfig(cfg *rest.Config, opts ...Config
 		logging.FromContext(ctx).Fatal(err)
 	}
 
+	// The --rekt.config file sets the flags which weren't set on the command
+	// line.
+	if err := applyConfigFile(ctx, config.Flags.Get(ctx)); err != nil {
+		logging.FromContext(ctx).Fatal(err)
+	}
+
 	if ipFilePath != nil && *ipFilePath != "" {
 		ctx = withImageProducer(ctx, file.ImageProducer(*ipFilePath))
 	}
@@ -82,6 +88,13 @@ func NewStandardGlobalEnvironmentWithRestConfig(cfg *rest.Config, opts ...Config
 		ctx = withNamespace(ctx, *testNamespace)
 	}
 
+	if *dryRun {
+		// In dry-run mode features are not run and no cluster is needed, the
+		// injection clients are not created.
+		ctx = withImageProducer(ctx, noopImageProducer)
+		return NewGlobalEnvironment(ctx)
+	}
+
 	// EnableInjectionOrDie will enable client injection, this is used by the
 	// testing framework for namespace management, and could be leveraged by
 	// features to pull Kubernetes clients or the test environment out of the
@@ -0,0 +1,94 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/state"
+)
+
+// stateDir is the directory, relative to $ARTIFACTS, of the state files.
+const stateDir = "state"
+
+// StateStoreFunc creates the state.Store of a feature that doesn't have one
+// when it is tested.
+type StateStoreFunc func(ctx context.Context, f *feature.Feature) (state.Store, error)
+
+// WithStateStore is an environment option to create the state.Store of the
+// features that don't have one with fn, instead of an in-memory
+// state.KVStore.
+func WithStateStore(fn StateStoreFunc) EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		if e, ok := env.(*MagicEnvironment); ok {
+			e.stateStore = fn
+		}
+		return ctx, nil
+	}
+}
+
+// WithFileStateStore is an environment option to save the state of each
+// feature to $ARTIFACTS/state/<feature>.json. The state saved by a previous run
+// of a feature with the same name is loaded, so that it survives across test
+// binaries. It fails when ARTIFACTS isn't set, since the state would be lost
+// with the temporary directory, use WithFileStateStoreIn instead.
+func WithFileStateStore() EnvOpts {
+	return func(ctx context.Context, env Environment) (context.Context, error) {
+		dir := os.Getenv("ARTIFACTS")
+		if dir == "" {
+			return ctx, errors.New("the file state store requires ARTIFACTS to be set, or a directory with WithFileStateStoreIn")
+		}
+		return WithFileStateStoreIn(filepath.Join(dir, stateDir))(ctx, env)
+	}
+}
+
+// WithFileStateStoreIn is an environment option to save the state of each
+// feature to <dir>/<feature>.json, as WithFileStateStore does.
+func WithFileStateStoreIn(dir string) EnvOpts {
+	return WithStateStore(func(ctx context.Context, f *feature.Feature) (state.Store, error) {
+		return state.NewFileStore(filepath.Join(dir, feature.MakeK8sNamePrefix(f.Name)+".json"))
+	})
+}
+
+// WithConfigMapStateStore is an environment option to save the state of each
+// feature to the ConfigMap state-<feature> of the test namespace. The state
+// saved by a previous run of a feature with the same name in the same
+// namespace, for example set with --environment.namespace, is loaded.
+func WithConfigMapStateStore() EnvOpts {
+	return WithStateStore(func(ctx context.Context, f *feature.Feature) (state.Store, error) {
+		return state.NewConfigMapStore(ctx, kubeclient.Get(ctx).CoreV1(), FromContext(ctx).Namespace(),
+			"state-"+feature.MakeK8sNamePrefix(f.Name))
+	})
+}
+
+func newKVStore(context.Context, *feature.Feature) (state.Store, error) {
+	return &state.KVStore{}, nil
+}
+
+// artifactsDir returns the directory where tests write their artifacts.
+func artifactsDir() string {
+	if dir := os.Getenv("ARTIFACTS"); dir != "" {
+		return dir
+	}
+	return os.TempDir()
+}
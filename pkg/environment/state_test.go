@@ -0,0 +1,82 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/environment/fakeenv"
+	"knative.dev/reconciler-test/pkg/feature"
+	testlog "knative.dev/reconciler-test/pkg/logging"
+	"knative.dev/reconciler-test/pkg/state"
+)
+
+func TestPersistentStateStores(t *testing.T) {
+	tests := map[string]func(t *testing.T) environment.EnvOpts{
+		"file": func(t *testing.T) environment.EnvOpts {
+			t.Setenv("ARTIFACTS", t.TempDir())
+			return environment.WithFileStateStore()
+		},
+		"directory": func(t *testing.T) environment.EnvOpts {
+			return environment.WithFileStateStoreIn(t.TempDir())
+		},
+		"configmap": func(t *testing.T) environment.EnvOpts {
+			return environment.WithConfigMapStateStore()
+		},
+	}
+	for name, newOpt := range tests {
+		t.Run(name, func(t *testing.T) {
+			opt := newOpt(t)
+			global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t),
+				fakeenv.WithKubeObjects(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "upgrade"}}),
+			)
+
+			// Each run is a new environment and a new feature, with the same
+			// name, as in separate test binaries.
+			before := feature.NewFeatureNamed("Upgrade")
+			before.Setup("create", func(ctx context.Context, t feature.T) {
+				state.SetOrFail(ctx, t, "sinkURI", "http://sink")
+			})
+			ctx, env := global.Environment(environment.WithNamespace("upgrade"), opt)
+			env.Test(ctx, t, before)
+			env.Finish()
+
+			var got string
+			after := feature.NewFeatureNamed("Upgrade")
+			after.Assert("assert", func(ctx context.Context, t feature.T) {
+				got = state.MustGet[string](ctx, t, "sinkURI")
+			})
+			ctx, env = global.Environment(environment.WithNamespace("upgrade"), opt)
+			env.Test(ctx, t, after)
+			env.Finish()
+
+			require.Equal(t, "http://sink", got)
+		})
+	}
+}
+
+func TestFileStateStoreRequiresArtifacts(t *testing.T) {
+	t.Setenv("ARTIFACTS", "")
+	_, err := environment.WithFileStateStore()(context.Background(), nil)
+	require.ErrorContains(t, err, "requires ARTIFACTS")
+}
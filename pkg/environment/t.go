@@ -17,7 +17,10 @@ limitations under the License.
 package environment
 
 import (
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"go.uber.org/atomic"
 
@@ -116,3 +119,136 @@ func (t *skippingT) SkipNow() {
 func (t *skippingT) Skipped() bool {
 	return t.skipped.Load()
 }
+
+// timeoutT wraps the T of a step that has a timeout. It reports the step
+// deadline, and it drops the calls performed by the step function after the
+// step timed out, since the underlying test might be completed by then.
+//
+// The step function runs on its own goroutine, where FailNow and SkipNow must
+// not be called, so the fatal calls only stop the step goroutine and they are
+// performed by stopIfFatal on the test goroutine.
+type timeoutT struct {
+	feature.T
+
+	deadline time.Time
+
+	mu      sync.RWMutex
+	closed  bool
+	failNow bool
+	skipNow bool
+}
+
+var _ feature.T = (*timeoutT)(nil)
+
+func newTimeoutT(t feature.T, deadline time.Time) *timeoutT {
+	return &timeoutT{
+		T:        t,
+		deadline: deadline,
+	}
+}
+
+func (t *timeoutT) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+}
+
+// do calls fn unless the step timed out, in which case it reports false.
+func (t *timeoutT) do(fn func()) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.closed {
+		return false
+	}
+	fn()
+	return true
+}
+
+// stop records the fatal call, unless the step timed out, and stops the step
+// goroutine.
+func (t *timeoutT) stop(fn func(), failNow bool) {
+	t.mu.Lock()
+	if !t.closed {
+		fn()
+		if failNow {
+			t.failNow = true
+		} else {
+			t.skipNow = true
+		}
+	}
+	t.mu.Unlock()
+	runtime.Goexit()
+}
+
+// stopIfFatal calls FailNow or SkipNow on the wrapped T if the step function
+// was stopped by one of them, it must be called from the test goroutine once
+// the step function returned.
+func (t *timeoutT) stopIfFatal() {
+	t.mu.RLock()
+	failNow, skipNow := t.failNow, t.skipNow
+	t.mu.RUnlock()
+
+	if failNow {
+		t.T.FailNow()
+	}
+	if skipNow {
+		t.T.SkipNow()
+	}
+}
+
+func (t *timeoutT) Log(args ...interface{}) {
+	t.do(func() { t.T.Log(args...) })
+}
+
+func (t *timeoutT) Logf(format string, args ...interface{}) {
+	t.do(func() { t.T.Logf(format, args...) })
+}
+
+func (t *timeoutT) Error(args ...interface{}) {
+	t.do(func() { t.T.Error(args...) })
+}
+
+func (t *timeoutT) Errorf(format string, args ...interface{}) {
+	t.do(func() { t.T.Errorf(format, args...) })
+}
+
+func (t *timeoutT) Fail() {
+	t.do(t.T.Fail)
+}
+
+func (t *timeoutT) Fatal(args ...interface{}) {
+	t.stop(func() { t.T.Error(args...) }, true)
+}
+
+func (t *timeoutT) Fatalf(format string, args ...interface{}) {
+	t.stop(func() { t.T.Errorf(format, args...) }, true)
+}
+
+func (t *timeoutT) FailNow() {
+	t.stop(func() {}, true)
+}
+
+func (t *timeoutT) Skip(args ...interface{}) {
+	t.stop(func() { t.T.Log(args...) }, false)
+}
+
+func (t *timeoutT) Skipf(format string, args ...interface{}) {
+	t.stop(func() { t.T.Logf(format, args...) }, false)
+}
+
+func (t *timeoutT) SkipNow() {
+	t.stop(func() {}, false)
+}
+
+func (t *timeoutT) Cleanup(f func()) {
+	t.do(func() { t.T.Cleanup(f) })
+}
+
+// Deadline reports the step deadline, or the test deadline when it comes
+// earlier.
+func (t *timeoutT) Deadline() (time.Time, bool) {
+	if deadline, ok := t.T.Deadline(); ok && deadline.Before(t.deadline) {
+		return deadline, true
+	}
+	return t.deadline, true
+}
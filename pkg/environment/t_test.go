@@ -0,0 +1,105 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/feature/featuretest"
+)
+
+func TestTimeoutT(t *testing.T) {
+	deadline := time.Now().Add(time.Minute)
+
+	ft := featuretest.New("step").Run(context.Background(), func(ctx context.Context, t feature.T) {
+		tt := newTimeoutT(t, deadline)
+		tt.Log("before")
+		tt.Error("failed before")
+		tt.close()
+		tt.Log("after")
+		tt.Error("failed after")
+		tt.Cleanup(func() {})
+		tt.Fatal("stopped after")
+		tt.Log("unreachable")
+	})
+
+	ft.AssertFailedWith(t, "failed before")
+	ft.AssertLogged(t, "before")
+	require.False(t, ft.FailedNow())
+	require.Equal(t, []string{"before"}, ft.Logs())
+	require.Equal(t, []string{"failed before"}, ft.Errors())
+}
+
+func TestTimeoutTDeadline(t *testing.T) {
+	stepDeadline := time.Now().Add(time.Minute)
+
+	got, ok := newTimeoutT(featuretest.New("step"), stepDeadline).Deadline()
+	require.True(t, ok)
+	require.Equal(t, stepDeadline, got)
+
+	testDeadline := stepDeadline.Add(-time.Second)
+	got, ok = newTimeoutT(featuretest.New("step", featuretest.WithDeadline(testDeadline)), stepDeadline).Deadline()
+	require.True(t, ok)
+	require.Equal(t, testDeadline, got)
+}
+
+func TestTimeoutTFatal(t *testing.T) {
+	ft := featuretest.New("step").Run(context.Background(), func(ctx context.Context, t feature.T) {
+		tt := newTimeoutT(t, time.Now().Add(time.Minute))
+		runStep(func() {
+			tt.Fatalf("stopped %d", 1)
+			tt.Log("unreachable")
+		})
+		// Only the step goroutine was stopped.
+		require.False(t, t.(*featuretest.T).FailedNow())
+		tt.stopIfFatal()
+		t.Log("unreachable")
+	})
+
+	ft.AssertFailedWith(t, "stopped 1")
+	require.True(t, ft.FailedNow())
+	require.Empty(t, ft.Logs())
+}
+
+func TestTimeoutTSkip(t *testing.T) {
+	ft := featuretest.New("step").Run(context.Background(), func(ctx context.Context, t feature.T) {
+		tt := newTimeoutT(t, time.Now().Add(time.Minute))
+		runStep(func() {
+			tt.Skip("not supported")
+		})
+		tt.stopIfFatal()
+		t.Log("unreachable")
+	})
+
+	ft.AssertSkipped(t)
+	require.Equal(t, []string{"not supported"}, ft.Logs())
+}
+
+// runStep calls fn on its own goroutine, like steps with a timeout.
+func runStep(fn func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	<-done
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import "testing"
+
+func TestNewFeatureTimings(t *testing.T) {
+	tests := map[string]struct {
+		artifacts   string
+		shard       Shard
+		timingsPath string
+		recorded    bool
+	}{
+		"local":     {},
+		"artifacts": {artifacts: t.TempDir(), recorded: true},
+		"shard":     {shard: Shard{Index: 1, Count: 2}, recorded: true},
+		"timings":   {timingsPath: "previous-run/rekt-timings", recorded: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("ARTIFACTS", tc.artifacts)
+			ft := newFeatureTimings("id", tc.shard, tc.timingsPath)
+			if got := ft != nil; got != tc.recorded {
+				t.Errorf("timings recorded = %t, want %t", got, tc.recorded)
+			}
+		})
+	}
+}
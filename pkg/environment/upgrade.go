@@ -0,0 +1,294 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/state"
+)
+
+// upgradeDir is the directory, relative to $ARTIFACTS, where the upgrade
+// features are recorded between test invocations.
+const upgradeDir = "upgrade"
+
+// UpgradePhase selects the phases of the upgrade features run by a test
+// invocation.
+type UpgradePhase string
+
+const (
+	// UpgradeAllPhases runs every phase in a single test invocation, around
+	// the upgrade function.
+	UpgradeAllPhases UpgradePhase = "all"
+	// UpgradePrePhase runs the phases before the upgrade and records the
+	// upgrade features for the UpgradePostPhase invocation.
+	UpgradePrePhase UpgradePhase = "pre"
+	// UpgradePostPhase runs the phases after the upgrade, in the namespace and
+	// with the references and state recorded by the UpgradePrePhase
+	// invocation.
+	UpgradePostPhase UpgradePhase = "post"
+)
+
+// UpgradeFunc upgrades the system under test.
+type UpgradeFunc func(ctx context.Context, t feature.T)
+
+// upgradeRecord carries an upgrade feature over from the UpgradePrePhase to
+// the UpgradePostPhase invocation.
+type upgradeRecord struct {
+	Namespace        string                     `json:"namespace"`
+	NamespaceCreated bool                       `json:"namespaceCreated"`
+	References       []corev1.ObjectReference   `json:"references"`
+	State            map[string]json.RawMessage `json:"state"`
+}
+
+// TestUpgrade tests the upgrade feature u in a new environment of global,
+// created with opts.
+//
+// Depending on the UpgradePhase of global, set by the rekt.upgrade.phase flag,
+// every phase runs around the upgrade function, or the phases before the
+// upgrade run and the upgrade feature is recorded to
+// $ARTIFACTS/upgrade/<name>.json, or the phases after the upgrade run from that
+// record. The namespace, the references and the state.Store carry over between
+// the phases, the resources are deleted once the phases after the upgrade
+// succeeded.
+//
+// In dry-run mode, the plans of the upgrade features are written, the upgrade
+// function doesn't run and nothing is recorded between the phases.
+//
+// The upgrade is selected as a whole by the feature, feature.tags and
+// rekt.shard settings of global, not of opts: its phases run together or not
+// at all.
+func TestUpgrade(t *testing.T, global GlobalEnvironment, u *feature.Upgrade, upgrade UpgradeFunc, opts ...EnvOpts) {
+	t.Helper()
+
+	phase := UpgradeAllPhases
+	dryRun := false
+	if mg, ok := global.(*MagicGlobalEnvironment); ok {
+		if mg.UpgradePhase != "" {
+			phase = mg.UpgradePhase
+		}
+		dryRun = mg.dryRun
+		if reason := mg.upgradeSkipReason(u); reason != "" {
+			t.Skip(reason)
+		}
+	}
+	path := filepath.Join(artifactsDir(), upgradeDir, feature.MakeK8sNamePrefix(u.Name)+".json")
+
+	switch phase {
+	case UpgradeAllPhases:
+		ctx, env := global.Environment(append(opts, Managed(t))...)
+		ctx = upgradeState(ctx, t, env, u, nil)
+		if !testUpgradeFeatures(ctx, t, env, u.BeforeUpgrade()) {
+			return
+		}
+		if upgrade != nil && !dryRun {
+			t.Run("Upgrade", func(t *testing.T) {
+				upgrade(ctx, t)
+			})
+			if t.Failed() {
+				return
+			}
+		}
+		testUpgradeFeatures(ctx, t, env, u.AfterUpgrade())
+
+	case UpgradePrePhase:
+		ctx, env := global.Environment(append(opts, Managed(t))...)
+		ctx = upgradeState(ctx, t, env, u, nil)
+		if !testUpgradeFeatures(ctx, t, env, u.BeforeUpgrade()) || dryRun {
+			return
+		}
+		mr, ok := env.(*MagicEnvironment)
+		if !ok {
+			t.Fatal("TestUpgrade: not a magic env")
+		}
+		record := upgradeRecord{
+			Namespace:        env.Namespace(),
+			NamespaceCreated: mr.namespaceCreated,
+			References:       env.References(),
+			State:            make(map[string]json.RawMessage),
+		}
+		store := state.FromContext(ctx)
+		lister, ok := store.(state.KeyLister)
+		if !ok {
+			t.Fatalf("TestUpgrade: the state store %T can't list its keys to record them", store)
+		}
+		for _, k := range lister.Keys(ctx) {
+			var v json.RawMessage
+			if err := store.Get(ctx, k, &v); err != nil {
+				t.Fatal(err)
+			}
+			record.State[k] = v
+		}
+		if err := writeUpgradeRecord(path, record); err != nil {
+			t.Fatal(err)
+		}
+		mr.handOver()
+		t.Logf("Upgrade %q recorded to %s", u.Name, path)
+
+	case UpgradePostPhase:
+		if dryRun {
+			ctx, env := global.Environment(append(opts, Managed(t))...)
+			ctx = upgradeState(ctx, t, env, u, nil)
+			testUpgradeFeatures(ctx, t, env, u.AfterUpgrade())
+			return
+		}
+		record, err := readUpgradeRecord(path)
+		if err != nil {
+			t.Fatalf("Failed to read the record of upgrade %q, the %q phase has to run first: %v", u.Name, UpgradePrePhase, err)
+		}
+		ctx, env := global.Environment(append(opts, WithNamespace(record.Namespace), Managed(t))...)
+		if mr, ok := env.(*MagicEnvironment); ok && record.NamespaceCreated {
+			mr.namespaceCreated = true
+		}
+		env.Reference(record.References...)
+		ctx = upgradeState(ctx, t, env, u, record.State)
+		if testUpgradeFeatures(ctx, t, env, u.AfterUpgrade()) {
+			if err := os.Remove(path); err != nil {
+				t.Log("Failed to remove the upgrade record: ", err)
+			}
+		}
+
+	default:
+		t.Fatalf("Unknown upgrade phase %q, expected one of %q, %q or %q", phase, UpgradeAllPhases, UpgradePrePhase, UpgradePostPhase)
+	}
+}
+
+// upgradeSkipReason returns why the upgrade is filtered out, if it is. The
+// upgrade matches --feature when its name or the name of one of its phases
+// does, its tags are the tags of all its phases, and it is assigned to a shard
+// by its name.
+func (mr *MagicGlobalEnvironment) upgradeSkipReason(u *feature.Upgrade) string {
+	phases := append(u.BeforeUpgrade(), u.AfterUpgrade()...)
+	if mr.FeatureMatch != nil {
+		matches := mr.FeatureMatch.MatchString(u.Name)
+		for _, f := range phases {
+			matches = matches || mr.FeatureMatch.MatchString(f.Name)
+		}
+		if !matches {
+			return fmt.Sprintf("--feature=%s doesn't match", mr.FeatureMatch.String())
+		}
+	}
+	var tags []string
+	for _, f := range phases {
+		tags = append(tags, f.GetTags()...)
+	}
+	if !mr.FeatureTags.Matches(tags) {
+		return fmt.Sprintf("upgrade tags %q don't satisfy --feature.tags=%q", tags, mr.FeatureTags.String())
+	}
+	if !mr.shard.Includes(u.Name) {
+		return fmt.Sprintf("upgrade is in shard %d/%d, not in --rekt.shard=%s", mr.shard.Of(u.Name), mr.shard.Count, mr.shard.String())
+	}
+	return ""
+}
+
+type upgradePhaseKey struct{}
+
+// withinUpgrade marks the features tested as phases of an upgrade, which
+// TestUpgrade already selected as a whole.
+func withinUpgrade(ctx context.Context) context.Context {
+	return context.WithValue(ctx, upgradePhaseKey{}, true)
+}
+
+func isUpgradePhase(ctx context.Context) bool {
+	v := ctx.Value(upgradePhaseKey{})
+	return v != nil && v.(bool)
+}
+
+// handOver hands the namespace and the resources of the environment over to
+// another invocation: Finish doesn't delete them, nor gives the namespace back
+// to the pool it was handed out by.
+func (mr *MagicEnvironment) handOver() {
+	mr.managedT = nil
+	mr.namespaceCreated = false
+	if mr.namespacePool != nil {
+		mr.namespacePool.replace()
+		mr.namespacePool = nil
+	}
+}
+
+// upgradeState creates the state.Store shared by the phases of the upgrade,
+// with the recorded state if any.
+func upgradeState(ctx context.Context, t *testing.T, env Environment, u *feature.Upgrade, recorded map[string]json.RawMessage) context.Context {
+	newStore := newKVStore
+	if mr, ok := env.(*MagicEnvironment); ok && mr.stateStore != nil {
+		newStore = mr.stateStore
+	}
+	store, err := newStore(ctx, feature.NewFeatureNamed(u.Name))
+	if err != nil {
+		t.Fatalf("Failed to create the state store of upgrade %q: %v", u.Name, err)
+	}
+	for k, v := range recorded {
+		if err := store.Set(ctx, k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return state.ContextWith(ctx, store)
+}
+
+// testUpgradeFeatures tests the features with steps, sharing the state.Store
+// of the context, until one fails.
+func testUpgradeFeatures(ctx context.Context, t *testing.T, env Environment, features []*feature.Feature) bool {
+	t.Helper()
+
+	ctx = withinUpgrade(ctx)
+	for _, f := range features {
+		if len(f.Steps) == 0 {
+			continue
+		}
+		f.State = state.FromContext(ctx)
+		env.Test(ctx, t, f)
+		if t.Failed() {
+			return false
+		}
+	}
+	return true
+}
+
+func writeUpgradeRecord(path string, record upgradeRecord) error {
+	b, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+func readUpgradeRecord(path string) (upgradeRecord, error) {
+	var record upgradeRecord
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return record, err
+	}
+	if err := json.Unmarshal(b, &record); err != nil {
+		return record, fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+	if record.Namespace == "" {
+		return record, errors.New("no namespace recorded")
+	}
+	return record, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/environment/fakeenv"
+	"knative.dev/reconciler-test/pkg/feature"
+	testlog "knative.dev/reconciler-test/pkg/logging"
+	"knative.dev/reconciler-test/pkg/state"
+)
+
+// recordingUpgrade returns an upgrade feature appending the steps it runs to
+// calls, along with the namespace and the state they see.
+func recordingUpgrade(calls *[]string) *feature.Upgrade {
+	u := feature.NewUpgrade("Recording")
+	u.PreUpgrade.Setup("create", func(ctx context.Context, t feature.T) {
+		*calls = append(*calls, "pre "+environment.FromContext(ctx).Namespace())
+		state.SetOrFail(ctx, t, "sink", "http://sink")
+	})
+	u.ContinualDuringUpgrade("probe", func(ctx context.Context, t feature.T) {
+		*calls = append(*calls, "start")
+		state.SetOrFail(ctx, t, "sent", 3)
+	}, func(ctx context.Context, t feature.T) {
+		*calls = append(*calls, "verify "+state.GetStringOrFail(ctx, t, "sink"))
+	})
+	u.PostUpgrade.Assert("assert", func(ctx context.Context, t feature.T) {
+		*calls = append(*calls, "post "+environment.FromContext(ctx).Namespace())
+		require.Equal(t, 3, state.MustGet[int](ctx, t, "sent"))
+	})
+	return u
+}
+
+func TestUpgradeAllPhases(t *testing.T) {
+	global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t))
+
+	var calls []string
+	var ns string
+	environment.TestUpgrade(t, global, recordingUpgrade(&calls), func(ctx context.Context, t feature.T) {
+		ns = environment.FromContext(ctx).Namespace()
+		calls = append(calls, "upgrade")
+	})
+
+	require.Equal(t, []string{
+		"pre " + ns,
+		"start",
+		"upgrade",
+		"post " + ns,
+		"verify http://sink",
+	}, calls)
+}
+
+func TestUpgradePrePostPhases(t *testing.T) {
+	tests := map[string][]fakeenv.Option{
+		"own namespace":                     nil,
+		"namespace pool":                    {fakeenv.WithNamespacePool(1, environment.NamespacePoolNeverReuse)},
+		"namespace pool reused after scrub": {fakeenv.WithNamespacePool(1, environment.NamespacePoolReuseAfterScrub)},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			artifacts := t.TempDir()
+			t.Setenv("ARTIFACTS", artifacts)
+			global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t), opts...)
+			record := filepath.Join(artifacts, "upgrade", feature.MakeK8sNamePrefix("Recording")+".json")
+
+			var calls []string
+			upgrade := func(ctx context.Context, t feature.T) {
+				t.Error("the upgrade doesn't run in separate phases")
+			}
+			// envCtx holds the fake clients of the global environment.
+			var envCtx context.Context
+			withContext := func(ctx context.Context, env environment.Environment) (context.Context, error) {
+				envCtx = ctx
+				return ctx, nil
+			}
+
+			// Each phase is a separate invocation, with a new upgrade feature.
+			global.(*environment.MagicGlobalEnvironment).UpgradePhase = environment.UpgradePrePhase
+			t.Run("pre", func(t *testing.T) {
+				environment.TestUpgrade(t, global, recordingUpgrade(&calls), upgrade, withContext)
+			})
+			require.FileExists(t, record)
+			require.NotEmpty(t, calls)
+			ns := calls[0][len("pre "):]
+			c := kubeclient.Get(envCtx)
+			_, err := c.CoreV1().Namespaces().Get(envCtx, ns, metav1.GetOptions{})
+			require.NoError(t, err, "the namespace is handed over to the post-upgrade phase")
+			t.Run("other environment", func(t *testing.T) {
+				_, env := global.Environment(environment.Managed(t))
+				require.NotEqual(t, ns, env.Namespace(), "the namespace left the pool")
+			})
+
+			global.(*environment.MagicGlobalEnvironment).UpgradePhase = environment.UpgradePostPhase
+			t.Run("post", func(t *testing.T) {
+				environment.TestUpgrade(t, global, recordingUpgrade(&calls), upgrade)
+			})
+			_, err = os.Stat(record)
+			require.True(t, os.IsNotExist(err), "the record is removed once the post-upgrade phase passed")
+
+			require.Equal(t, []string{
+				"pre " + ns,
+				"start",
+				"post " + ns,
+				"verify http://sink",
+			}, calls)
+			_, err = c.CoreV1().Namespaces().Get(envCtx, ns, metav1.GetOptions{})
+			require.True(t, apierrors.IsNotFound(err), "namespace %s not deleted: %v", ns, err)
+
+			environment.DrainNamespacePool(global)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assert
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cetest "github.com/cloudevents/sdk-go/v2/test"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/environment/fakeenv"
+	"knative.dev/reconciler-test/pkg/eventshub"
+	"knative.dev/reconciler-test/pkg/feature/featuretest"
+	"knative.dev/reconciler-test/pkg/k8s"
+	testlog "knative.dev/reconciler-test/pkg/logging"
+)
+
+// storeContext returns a context holding the store "hub", with one received
+// event for each id.
+func storeContext(t *testing.T, ids ...string) context.Context {
+	ctx := testlog.WithTestLogger(context.Background(), t)
+	ctx, _ = fakeenv.NewGlobalEnvironment(ctx).Environment(k8s.WithEventListener)
+	ctx = environment.ContextWithPollTimings(ctx, 10*time.Millisecond, 50*time.Millisecond)
+	t.Cleanup(k8s.EventListenerFromContext(ctx).Stop)
+
+	// The zero Store collects the events observed for the pod with an empty
+	// name.
+	store := &eventshub.Store{}
+	k8s.EventListenerFromContext(ctx).AddHandler("hub", store)
+	for _, id := range ids {
+		event := cloudevents.NewEvent()
+		event.SetID(id)
+		event.SetType("dev.knative.test")
+		event.SetSource("test")
+		b, err := json.Marshal(eventshub.EventInfo{Kind: eventshub.EventReceived, Event: &event})
+		require.NoError(t, err)
+		store.Handle(&corev1.Event{
+			Type:           corev1.EventTypeNormal,
+			Reason:         eventshub.CloudEventObservedReason,
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod"},
+			Message:        string(b),
+		})
+	}
+	return ctx
+}
+
+func TestOnStoreExact(t *testing.T) {
+	ctx := storeContext(t, "1", "2")
+
+	featuretest.New("exact").
+		Run(ctx, OnStore("hub").MatchReceivedEvent(cetest.HasId("1")).Exact(1)).
+		AssertPassed(t)
+
+	featuretest.New("too few").
+		Run(ctx, OnStore("hub").MatchReceivedEvent(cetest.HasId("3")).Exact(1)).
+		AssertFailedWith(t, "saw 0/1 matching events")
+}
+
+func TestOnStoreNot(t *testing.T) {
+	ctx := storeContext(t, "1")
+
+	featuretest.New("not sent").
+		Run(ctx, OnStore("hub").MatchSentEvent(cetest.HasId("1")).Not()).
+		AssertPassed(t)
+
+	featuretest.New("received").
+		Run(ctx, OnStore("hub").MatchReceivedEvent(cetest.HasId("1")).Not()).
+		AssertFailedWith(t, "Unexpected matches on eventshub")
+}
@@ -115,7 +115,7 @@ func Install(name string, options ...EventsHubOption) feature.StepFn {
 		// When forwarder is included we need to rename the eventshub service to
 		// prevent conflict with the forwarder service.
 		if withForwarder {
-			serviceName = feature.MakeRandomK8sName(name)
+			serviceName = feature.RandFromContext(ctx).MakeRandomK8sName(name)
 		}
 
 		cfg := map[string]any{
@@ -18,6 +18,8 @@ package feature
 
 import (
 	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type envKey struct{}
@@ -36,3 +38,37 @@ func FromContext(ctx context.Context) *Feature {
 	}
 	return nil
 }
+
+type deletePropagationKey struct{}
+
+// ContextWithDeletePropagation overrides the propagation policy used by
+// DeleteResources, which is foreground by default. Background propagation is
+// useful when no garbage collector runs, for example against an API server
+// started by envtest.
+func ContextWithDeletePropagation(ctx context.Context, policy metav1.DeletionPropagation) context.Context {
+	return context.WithValue(ctx, deletePropagationKey{}, policy)
+}
+
+func deletePropagationFromContext(ctx context.Context) metav1.DeletionPropagation {
+	if p, ok := ctx.Value(deletePropagationKey{}).(metav1.DeletionPropagation); ok {
+		return p
+	}
+	return metav1.DeletePropagationForeground
+}
+
+type randKey struct{}
+
+// ContextWithRand decorates the given context with the Rand generating the
+// random names of an environment.
+func ContextWithRand(ctx context.Context, r *Rand) context.Context {
+	return context.WithValue(ctx, randKey{}, r)
+}
+
+// RandFromContext returns the Rand from Context, if not found RandFromContext
+// will return the DefaultRand.
+func RandFromContext(ctx context.Context) *Rand {
+	if r, ok := ctx.Value(randKey{}).(*Rand); ok {
+		return r
+	}
+	return DefaultRand()
+}
@@ -23,6 +23,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -43,8 +44,12 @@ type Feature struct {
 	// Contains all the resources created as part of this Feature.
 	refs   []corev1.ObjectReference
 	refsMu sync.Mutex
+	// Contains the Teardown steps deferred while running this Feature.
+	deferred   []Step
+	deferredMu sync.Mutex
 
 	groups []*Feature
+	tags   []string
 }
 
 func (f *Feature) MarshalJSON() ([]byte, error) {
@@ -57,11 +62,13 @@ func (f *Feature) marshalJSON(pretty bool) ([]byte, error) {
 
 	in := struct {
 		Name  string                   `json:"name"`
+		Tags  []string                 `json:"tags,omitempty"`
 		Steps []Step                   `json:"steps"`
 		State state.Store              `json:"state"`
 		Refs  []corev1.ObjectReference `json:"refs"`
 	}{
 		Name:  f.Name,
+		Tags:  f.tags,
 		Steps: f.Steps,
 		State: f.State,
 		Refs:  f.refs,
@@ -153,6 +160,31 @@ type Step struct {
 	L    Levels `json:"levels"`
 	T    Timing `json:"timing"`
 	Fn   StepFn `json:"-"`
+	// Deps are the names of the steps in the same timing that have to
+	// complete before this step starts.
+	Deps []string `json:"after,omitempty"`
+	// Timeout is the maximum duration of the step, zero means that the
+	// environment default applies.
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// StepOption configures a Step when it is added to a Feature.
+type StepOption func(s *Step)
+
+// After declares that the step depends on the steps in the same timing with
+// the given names, so it only starts once all of them succeeded.
+func After(names ...string) StepOption {
+	return func(s *Step) {
+		s.Deps = append(s.Deps, names...)
+	}
+}
+
+// Timeout sets the maximum duration of the step. The context passed to the
+// step is cancelled once the timeout passes and the step fails.
+func Timeout(d time.Duration) StepOption {
+	return func(s *Step) {
+		s.Timeout = d
+	}
 }
 
 type Steps []Step
@@ -210,9 +242,9 @@ func DeleteResources(ctx context.Context, t T, refs []corev1.ObjectReference) er
 	refsDeleted := map[corev1.ObjectReference]bool{}
 
 	deleteOptions := &metav1.DeleteOptions{}
-	// Set delete propagation policy to foreground
-	foregroundDeletePropagation := metav1.DeletePropagationForeground
-	deleteOptions.PropagationPolicy = &foregroundDeletePropagation
+	// Set delete propagation policy, foreground unless overridden
+	deletePropagation := deletePropagationFromContext(ctx)
+	deleteOptions.PropagationPolicy = &deletePropagation
 
 	// First, try to delete all the refs at least once
 	for _, ref := range refs {
@@ -319,25 +351,25 @@ func (sr ShouldRun) AsStepFn() StepFn {
 }
 
 // Prerequisite adds a step function to the feature set at the Prerequisite timing phase.
-func (f *Feature) Prerequisite(name string, fn ShouldRun) {
-	f.AddStep(Step{
+func (f *Feature) Prerequisite(name string, fn ShouldRun, opts ...StepOption) {
+	f.AddStep(newStep(Step{
 		Name: name,
 		S:    Any,
 		L:    All,
 		T:    Prerequisite,
 		Fn:   fn.AsStepFn(),
-	})
+	}, opts...))
 }
 
 // Setup adds a step function to the feature set at the Setup timing phase.
-func (f *Feature) Setup(name string, fn StepFn) {
-	f.AddStep(Step{
+func (f *Feature) Setup(name string, fn StepFn, opts ...StepOption) {
+	f.AddStep(newStep(Step{
 		Name: name,
 		S:    Any,
 		L:    All,
 		T:    Setup,
 		Fn:   fn,
-	})
+	}, opts...))
 }
 
 // Group add a new group to the feature, groups are executed in the order they are inserted and
@@ -361,53 +393,112 @@ func (f *Feature) GroupF(other *Feature) {
 }
 
 // Requirement adds a step function to the feature set at the Requirement timing phase.
-func (f *Feature) Requirement(name string, fn StepFn) {
-	f.AddStep(Step{
+func (f *Feature) Requirement(name string, fn StepFn, opts ...StepOption) {
+	f.AddStep(newStep(Step{
 		Name: name,
 		S:    Any,
 		L:    All,
 		T:    Requirement,
 		Fn:   fn,
-	})
+	}, opts...))
 }
 
 // Assert is a shortcut for Stable().Must(name, fn),
 // useful for developing integration tests that don't require assertion levels.
-func (f *Feature) Assert(name string, fn StepFn) {
-	f.AddStep(Step{
+func (f *Feature) Assert(name string, fn StepFn, opts ...StepOption) {
+	f.AddStep(newStep(Step{
 		Name: name,
 		S:    Stable,
 		L:    Must,
 		T:    Assert,
 		Fn:   fn,
-	})
+	}, opts...))
 }
 
 // Assert adds a step function to the feature set at the Assert timing phase.
-func (a *Asserter) Assert(l Levels, name string, fn StepFn) {
-	a.f.AddStep(Step{
+func (a *Asserter) Assert(l Levels, name string, fn StepFn, opts ...StepOption) {
+	a.f.AddStep(newStep(Step{
 		Name: fmt.Sprintf("%s %s", a.name, name),
 		S:    a.s,
 		L:    l,
 		T:    Assert,
 		Fn:   fn,
-	})
+	}, opts...))
 }
 
 // Teardown adds a step function to the feature set at the Teardown timing phase.
-func (f *Feature) Teardown(name string, fn StepFn) {
-	f.AddStep(Step{
+func (f *Feature) Teardown(name string, fn StepFn, opts ...StepOption) {
+	f.AddStep(newStep(Step{
 		Name: name,
 		S:    Any,
 		L:    All,
 		T:    Teardown,
 		Fn:   fn,
-	})
+	}, opts...))
+}
+
+// DeferTeardown adds a step function to run at the Teardown timing of the
+// running feature, it is called from a step function to undo a change to the
+// cluster, for example. Unlike the steps added with Teardown, deferred steps
+// run even when a previous timing failed and --teardown.on.fail is not set,
+// one at a time after the other Teardown steps, in the reverse order they were
+// deferred. Steps deferred by a deferred step run after it.
+func (f *Feature) DeferTeardown(name string, fn StepFn, opts ...StepOption) {
+	f.deferredMu.Lock()
+	defer f.deferredMu.Unlock()
+
+	f.deferred = append(f.deferred, newStep(Step{
+		Name: name,
+		S:    Any,
+		L:    All,
+		T:    Teardown,
+		Fn:   fn,
+	}, opts...))
+}
+
+// TakeDeferredTeardown removes and returns the steps deferred with
+// DeferTeardown, in the order they run, this is for rekt internal use only.
+func (f *Feature) TakeDeferredTeardown() []Step {
+	f.deferredMu.Lock()
+	defer f.deferredMu.Unlock()
+
+	steps := make([]Step, 0, len(f.deferred))
+	for i := len(f.deferred) - 1; i >= 0; i-- {
+		steps = append(steps, f.deferred[i])
+	}
+	f.deferred = nil
+	return steps
+}
+
+func newStep(s Step, opts ...StepOption) Step {
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
 }
 
 // AddStep appends one or more steps to the Feature.
+//
+// AddStep panics if the dependencies declared with After form a cycle.
 func (f *Feature) AddStep(step ...Step) {
 	f.Steps = append(f.Steps, step...)
+	if err := Steps(f.Steps).checkCycles(); err != nil {
+		panic(fmt.Errorf("feature %q: %w", f.Name, err))
+	}
+}
+
+// Clone returns a copy of the feature, with its steps, groups and tags, but
+// without its State and references, to test the feature again from scratch.
+func (f *Feature) Clone() *Feature {
+	c := &Feature{
+		Name:  f.Name,
+		Steps: append([]Step(nil), f.Steps...),
+		tags:  append([]string(nil), f.tags...),
+	}
+	for _, g := range f.groups {
+		c.groups = append(c.groups, g.Clone())
+	}
+	return c
 }
 
 // GetGroups returns sub-features, this is for rekt internal use only.
@@ -417,11 +508,11 @@ func (f *Feature) GetGroups() []*Feature {
 
 // Assertable is a fluent interface based on Levels for creating an Assert step.
 type Assertable interface {
-	Must(name string, fn StepFn) Assertable
-	Should(name string, fn StepFn) Assertable
-	May(name string, fn StepFn) Assertable
-	MustNot(name string, fn StepFn) Assertable
-	ShouldNot(name string, fn StepFn) Assertable
+	Must(name string, fn StepFn, opts ...StepOption) Assertable
+	Should(name string, fn StepFn, opts ...StepOption) Assertable
+	May(name string, fn StepFn, opts ...StepOption) Assertable
+	MustNot(name string, fn StepFn, opts ...StepOption) Assertable
+	ShouldNot(name string, fn StepFn, opts ...StepOption) Assertable
 }
 
 // Alpha is a fluent style method for creating an Assert step in Alpha State.
@@ -453,27 +544,27 @@ type Asserter struct {
 	s    States
 }
 
-func (a *Asserter) Must(name string, fn StepFn) Assertable {
-	a.Assert(Must, name, fn)
+func (a *Asserter) Must(name string, fn StepFn, opts ...StepOption) Assertable {
+	a.Assert(Must, name, fn, opts...)
 	return a
 }
 
-func (a *Asserter) Should(name string, fn StepFn) Assertable {
-	a.Assert(Should, name, fn)
+func (a *Asserter) Should(name string, fn StepFn, opts ...StepOption) Assertable {
+	a.Assert(Should, name, fn, opts...)
 	return a
 }
 
-func (a *Asserter) May(name string, fn StepFn) Assertable {
-	a.Assert(May, name, fn)
+func (a *Asserter) May(name string, fn StepFn, opts ...StepOption) Assertable {
+	a.Assert(May, name, fn, opts...)
 	return a
 }
 
-func (a *Asserter) MustNot(name string, fn StepFn) Assertable {
-	a.Assert(MustNot, name, fn)
+func (a *Asserter) MustNot(name string, fn StepFn, opts ...StepOption) Assertable {
+	a.Assert(MustNot, name, fn, opts...)
 	return a
 }
 
-func (a *Asserter) ShouldNot(name string, fn StepFn) Assertable {
-	a.Assert(ShouldNot, name, fn)
+func (a *Asserter) ShouldNot(name string, fn StepFn, opts ...StepOption) Assertable {
+	a.Assert(ShouldNot, name, fn, opts...)
 	return a
 }
@@ -0,0 +1,335 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package featuretest provides an in-memory feature.T to unit test StepFn
+// without a cluster or a testing.T.
+package featuretest
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"knative.dev/reconciler-test/pkg/feature"
+)
+
+// EntryKind is the kind of a recorded Entry.
+type EntryKind string
+
+const (
+	LogEntry   EntryKind = "log"
+	ErrorEntry EntryKind = "error"
+	FatalEntry EntryKind = "fatal"
+	SkipEntry  EntryKind = "skip"
+)
+
+// Entry is a message reported to the T.
+type Entry struct {
+	Kind    EntryKind
+	Message string
+}
+
+func (e Entry) String() string {
+	return fmt.Sprintf("[%s] %s", e.Kind, e.Message)
+}
+
+// T is a thread-safe feature.T that records what it is told.
+//
+// Like testing.T, FailNow, Fatal, Fatalf, SkipNow, Skip and Skipf stop the
+// calling goroutine with runtime.Goexit, so the StepFn has to be called with
+// Run.
+type T struct {
+	name     string
+	deadline time.Time
+
+	mu       sync.Mutex
+	entries  []Entry
+	failed   bool
+	failNow  bool
+	skipped  bool
+	cleanups []func()
+	finished bool
+}
+
+var _ feature.T = (*T)(nil)
+
+// Option configures a T.
+type Option func(t *T)
+
+// WithDeadline sets the deadline reported by the T.
+func WithDeadline(deadline time.Time) Option {
+	return func(t *T) {
+		t.deadline = deadline
+	}
+}
+
+// New creates a T with the given name.
+func New(name string, opts ...Option) *T {
+	t := &T{name: name}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Run calls fn with t in its own goroutine and waits for it to return or to be
+// stopped by FailNow or SkipNow, a panic of fn is recorded as a fatal error.
+// The cleanup functions are called afterwards in last added, first called
+// order, like testing.T does when a test completes.
+func (t *T) Run(ctx context.Context, fn feature.StepFn) *T {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				t.record(FatalEntry, fmt.Sprintf("panic: %v", r))
+			}
+		}()
+		fn(ctx, t)
+	}()
+	<-done
+
+	t.mu.Lock()
+	cleanups := t.cleanups
+	t.cleanups = nil
+	t.finished = true
+	t.mu.Unlock()
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+	return t
+}
+
+func (t *T) record(kind EntryKind, msg string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = append(t.entries, Entry{Kind: kind, Message: msg})
+	switch kind {
+	case ErrorEntry:
+		t.failed = true
+	case FatalEntry:
+		t.failed = true
+		t.failNow = true
+	case SkipEntry:
+		t.skipped = true
+	}
+}
+
+func (t *T) Name() string {
+	return t.name
+}
+
+func (t *T) Log(args ...interface{}) {
+	t.record(LogEntry, sprint(args...))
+}
+
+func (t *T) Logf(format string, args ...interface{}) {
+	t.record(LogEntry, fmt.Sprintf(format, args...))
+}
+
+func (t *T) Error(args ...interface{}) {
+	t.record(ErrorEntry, sprint(args...))
+}
+
+func (t *T) Errorf(format string, args ...interface{}) {
+	t.record(ErrorEntry, fmt.Sprintf(format, args...))
+}
+
+func (t *T) Fail() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failed = true
+}
+
+func (t *T) Fatal(args ...interface{}) {
+	t.record(FatalEntry, sprint(args...))
+	runtime.Goexit()
+}
+
+func (t *T) Fatalf(format string, args ...interface{}) {
+	t.record(FatalEntry, fmt.Sprintf(format, args...))
+	runtime.Goexit()
+}
+
+func (t *T) FailNow() {
+	t.mu.Lock()
+	t.failed = true
+	t.failNow = true
+	t.mu.Unlock()
+	runtime.Goexit()
+}
+
+func (t *T) Skip(args ...interface{}) {
+	t.record(SkipEntry, sprint(args...))
+	runtime.Goexit()
+}
+
+func (t *T) Skipf(format string, args ...interface{}) {
+	t.record(SkipEntry, fmt.Sprintf(format, args...))
+	runtime.Goexit()
+}
+
+func (t *T) SkipNow() {
+	t.mu.Lock()
+	t.skipped = true
+	t.mu.Unlock()
+	runtime.Goexit()
+}
+
+func (t *T) Failed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failed
+}
+
+// FailedNow reports whether the T was stopped by FailNow, Fatal or Fatalf.
+func (t *T) FailedNow() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failNow
+}
+
+func (t *T) Skipped() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.skipped
+}
+
+// Cleanup registers f to be called when Run completes, it panics if Run
+// already completed.
+func (t *T) Cleanup(f func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finished {
+		panic("featuretest: Cleanup called after the step completed")
+	}
+	t.cleanups = append(t.cleanups, f)
+}
+
+// Deadline reports the deadline set with WithDeadline, if any.
+func (t *T) Deadline() (time.Time, bool) {
+	return t.deadline, !t.deadline.IsZero()
+}
+
+// Entries returns all the recorded entries in order.
+func (t *T) Entries() []Entry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Entry(nil), t.entries...)
+}
+
+// Messages returns the messages of the recorded entries of the given kinds.
+func (t *T) Messages(kinds ...EntryKind) []string {
+	var msgs []string
+	for _, e := range t.Entries() {
+		for _, k := range kinds {
+			if e.Kind == k {
+				msgs = append(msgs, e.Message)
+			}
+		}
+	}
+	return msgs
+}
+
+// Logs returns the logged messages.
+func (t *T) Logs() []string {
+	return t.Messages(LogEntry)
+}
+
+// Errors returns the error messages, including the fatal ones.
+func (t *T) Errors() []string {
+	return t.Messages(ErrorEntry, FatalEntry)
+}
+
+// Output returns all the recorded entries, one per line.
+func (t *T) Output() string {
+	sb := &strings.Builder{}
+	for _, e := range t.Entries() {
+		sb.WriteString(e.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// AssertPassed fails tb if t failed or was skipped.
+func (t *T) AssertPassed(tb testing.TB) {
+	tb.Helper()
+	if t.Failed() || t.Skipped() {
+		tb.Errorf("expected %q to pass, failed: %t, skipped: %t, output:\n%s", t.name, t.Failed(), t.Skipped(), t.Output())
+	}
+}
+
+// AssertFailed fails tb if t didn't fail.
+func (t *T) AssertFailed(tb testing.TB) {
+	tb.Helper()
+	if !t.Failed() {
+		tb.Errorf("expected %q to fail, output:\n%s", t.name, t.Output())
+	}
+}
+
+// AssertFailedWith fails tb if t didn't fail with an error containing substr.
+func (t *T) AssertFailedWith(tb testing.TB, substr string) {
+	tb.Helper()
+	t.AssertFailed(tb)
+	if !contains(t.Errors(), substr) {
+		tb.Errorf("expected %q to fail with an error containing %q, output:\n%s", t.name, substr, t.Output())
+	}
+}
+
+// AssertSkipped fails tb if t wasn't skipped.
+func (t *T) AssertSkipped(tb testing.TB) {
+	tb.Helper()
+	if !t.Skipped() {
+		tb.Errorf("expected %q to be skipped, output:\n%s", t.name, t.Output())
+	}
+}
+
+// AssertSkippedWith fails tb if t wasn't skipped with a message containing
+// substr.
+func (t *T) AssertSkippedWith(tb testing.TB, substr string) {
+	tb.Helper()
+	t.AssertSkipped(tb)
+	if !contains(t.Messages(SkipEntry), substr) {
+		tb.Errorf("expected %q to be skipped with a message containing %q, output:\n%s", t.name, substr, t.Output())
+	}
+}
+
+// AssertLogged fails tb if t didn't log a message containing substr.
+func (t *T) AssertLogged(tb testing.TB, substr string) {
+	tb.Helper()
+	if !contains(t.Logs(), substr) {
+		tb.Errorf("expected %q to log a message containing %q, output:\n%s", t.name, substr, t.Output())
+	}
+}
+
+func contains(msgs []string, substr string) bool {
+	for _, m := range msgs {
+		if strings.Contains(m, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// sprint formats args like testing.T.Log does.
+func sprint(args ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
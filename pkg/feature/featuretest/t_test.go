@@ -0,0 +1,180 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featuretest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"knative.dev/reconciler-test/pkg/feature"
+)
+
+// errorsTB records the errors reported by the assertions.
+type errorsTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *errorsTB) Helper() {}
+
+func (tb *errorsTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestT(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      feature.StepFn
+		assert  func(tb testing.TB, rt *T)
+		failed  bool
+		skipped bool
+		failNow bool
+		entries []Entry
+	}{{
+		name: "passes",
+		fn: func(ctx context.Context, t feature.T) {
+			t.Log("hello", 42)
+		},
+		assert: func(tb testing.TB, rt *T) {
+			rt.AssertPassed(tb)
+			rt.AssertLogged(tb, "hello 42")
+		},
+		entries: []Entry{{Kind: LogEntry, Message: "hello 42"}},
+	}, {
+		name: "errors",
+		fn: func(ctx context.Context, t feature.T) {
+			t.Errorf("first %d", 1)
+			t.Error("second")
+		},
+		assert: func(tb testing.TB, rt *T) {
+			rt.AssertFailedWith(tb, "first 1")
+			rt.AssertFailedWith(tb, "second")
+		},
+		failed:  true,
+		entries: []Entry{{Kind: ErrorEntry, Message: "first 1"}, {Kind: ErrorEntry, Message: "second"}},
+	}, {
+		name: "fatal stops the step",
+		fn: func(ctx context.Context, t feature.T) {
+			t.Fatalf("boom %s", "now")
+			t.Log("unreachable")
+		},
+		assert: func(tb testing.TB, rt *T) {
+			rt.AssertFailedWith(tb, "boom now")
+		},
+		failed:  true,
+		failNow: true,
+		entries: []Entry{{Kind: FatalEntry, Message: "boom now"}},
+	}, {
+		name: "skip stops the step",
+		fn: func(ctx context.Context, t feature.T) {
+			t.Skip("not supported")
+			t.Error("unreachable")
+		},
+		assert: func(tb testing.TB, rt *T) {
+			rt.AssertSkippedWith(tb, "not supported")
+		},
+		skipped: true,
+		entries: []Entry{{Kind: SkipEntry, Message: "not supported"}},
+	}, {
+		name: "panic",
+		fn: func(ctx context.Context, t feature.T) {
+			panic("oops")
+		},
+		assert: func(tb testing.TB, rt *T) {
+			rt.AssertFailedWith(tb, "panic: oops")
+		},
+		failed:  true,
+		failNow: true,
+		entries: []Entry{{Kind: FatalEntry, Message: "panic: oops"}},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := New(tt.name).Run(context.Background(), tt.fn)
+
+			require.Equal(t, tt.failed, rt.Failed())
+			require.Equal(t, tt.skipped, rt.Skipped())
+			require.Equal(t, tt.failNow, rt.FailedNow())
+			require.Equal(t, tt.entries, rt.Entries())
+			tt.assert(t, rt)
+		})
+	}
+}
+
+func TestAssertionsFail(t *testing.T) {
+	rt := New("step").Run(context.Background(), func(ctx context.Context, t feature.T) {
+		t.Error("unexpected")
+	})
+
+	tb := &errorsTB{}
+	rt.AssertPassed(tb)
+	rt.AssertFailedWith(tb, "expected something else")
+	rt.AssertSkipped(tb)
+	rt.AssertLogged(tb, "unexpected")
+
+	require.Len(t, tb.errors, 4)
+	require.Contains(t, tb.errors[0], `expected "step" to pass, failed: true, skipped: false`)
+	require.Contains(t, tb.errors[1], `containing "expected something else"`)
+	require.Contains(t, tb.errors[1], "[error] unexpected")
+}
+
+func TestCleanups(t *testing.T) {
+	var order []int
+	rt := New("step").Run(context.Background(), func(ctx context.Context, t feature.T) {
+		t.Cleanup(func() { order = append(order, 1) })
+		t.Cleanup(func() { order = append(order, 2) })
+		t.FailNow()
+	})
+
+	rt.AssertFailed(t)
+	require.True(t, rt.FailedNow())
+	require.Equal(t, []int{2, 1}, order)
+	require.Panics(t, func() { rt.Cleanup(func() {}) })
+}
+
+func TestConcurrentUse(t *testing.T) {
+	rt := New("step").Run(context.Background(), func(ctx context.Context, t feature.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				t.Logf("goroutine %d", i)
+				_ = t.Failed()
+			}(i)
+		}
+		wg.Wait()
+	})
+
+	require.Len(t, rt.Logs(), 10)
+	rt.AssertPassed(t)
+}
+
+func TestDeadline(t *testing.T) {
+	_, ok := New("step").Deadline()
+	require.False(t, ok)
+
+	deadline := time.Now().Add(time.Minute)
+	got, ok := New("step", WithDeadline(deadline)).Deadline()
+	require.True(t, ok)
+	require.Equal(t, deadline, got)
+}
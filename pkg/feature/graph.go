@@ -0,0 +1,111 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feature
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DependencyGraph resolves the dependencies declared with After.
+//
+// The returned slice has an entry for each step holding the indexes of the
+// steps it depends on. Dependencies are matched by step name against the steps
+// with the same timing, a name that doesn't match any of them or a cycle
+// between steps is reported as an error.
+func (ss Steps) DependencyGraph() ([][]int, error) {
+	deps, err := ss.dependencies(true)
+	if err != nil {
+		return nil, err
+	}
+	if err := detectCycle(ss, deps); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+// checkCycles reports cycles between steps, ignoring dependencies on steps that
+// might be added later.
+func (ss Steps) checkCycles() error {
+	deps, _ := ss.dependencies(false)
+	return detectCycle(ss, deps)
+}
+
+func (ss Steps) dependencies(strict bool) ([][]int, error) {
+	deps := make([][]int, len(ss))
+	for i, s := range ss {
+		for _, name := range s.Deps {
+			found := false
+			for j, other := range ss {
+				if i != j && other.T == s.T && other.Name == name {
+					deps[i] = append(deps[i], j)
+					found = true
+				}
+			}
+			if !found && strict {
+				return nil, fmt.Errorf("step %q depends on unknown %s step %q", s.Name, s.T, name)
+			}
+		}
+	}
+	return deps, nil
+}
+
+func detectCycle(ss Steps, deps [][]int) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make([]int, len(ss))
+	var path []int
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch marks[i] {
+		case visited:
+			return nil
+		case visiting:
+			start := len(path) - 1
+			for path[start] != i {
+				start--
+			}
+			names := make([]string, 0, len(path)-start+1)
+			for _, k := range path[start:] {
+				names = append(names, ss[k].Name)
+			}
+			names = append(names, ss[i].Name)
+			return fmt.Errorf("dependency cycle between %s steps: %s", ss[i].T, strings.Join(names, " -> "))
+		}
+		marks[i] = visiting
+		path = append(path, i)
+		for _, d := range deps[i] {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		marks[i] = visited
+		return nil
+	}
+
+	for i := range ss {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
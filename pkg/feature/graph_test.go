@@ -0,0 +1,69 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feature
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func noop(context.Context, T) {}
+
+func TestDependencyGraph(t *testing.T) {
+	f := NewFeature()
+	f.Setup("install broker", noop)
+	f.Setup("install trigger", noop, After("install broker"))
+	f.Setup("install source", noop, After("install broker", "install trigger"))
+	f.Assert("install broker", noop)
+
+	deps, err := Steps(f.Steps).DependencyGraph()
+	require.NoError(t, err)
+	require.Equal(t, [][]int{nil, {0}, {0, 1}, nil}, deps)
+}
+
+func TestDependencyGraphUnknownStep(t *testing.T) {
+	f := NewFeature()
+	f.Setup("install trigger", noop, After("install broker"))
+	f.Assert("install broker", noop)
+
+	_, err := Steps(f.Steps).DependencyGraph()
+	require.ErrorContains(t, err, `step "install trigger" depends on unknown Setup step "install broker"`)
+}
+
+func TestAddStepCycle(t *testing.T) {
+	f := NewFeatureNamed("cycle")
+	f.Setup("a", noop, After("c"))
+	f.Setup("b", noop, After("a"))
+
+	require.PanicsWithError(t, `feature "cycle": dependency cycle between Setup steps: a -> c -> b -> a`, func() {
+		f.Setup("c", noop, After("b"))
+	})
+}
+
+func TestStepsStringWithDependencies(t *testing.T) {
+	f := NewFeature()
+	f.Stable("broker").
+		Must("accepts events", noop).
+		Must("delivers events", noop, After("broker accepts events"))
+
+	require.JSONEq(t, `[
+		{"name": "broker accepts events", "states": "Stable", "levels": "MUST", "timing": "Assert"},
+		{"name": "broker delivers events", "states": "Stable", "levels": "MUST", "timing": "Assert", "after": ["broker accepts events"]}
+	]`, Steps(f.Steps).String())
+}
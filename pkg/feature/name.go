@@ -17,9 +17,7 @@ limitations under the License.
 package feature
 
 import (
-	"math/rand"
 	"strings"
-	"time"
 	"unicode"
 
 	"knative.dev/pkg/kmeta"
@@ -32,14 +30,6 @@ const (
 	testNamePrefix = "Test"
 )
 
-func init() {
-	// Properly seed the random number generator so RandomString() is actually random.
-	// Otherwise, rerunning tests will generate the same names for the test resources, causing conflicts with
-	// already existing resources.
-	seed := time.Now().UTC().UnixNano()
-	rand.Seed(seed)
-}
-
 type Namer interface {
 	Name() string
 }
@@ -63,17 +53,13 @@ func AppendRandomString(prefix string) string {
 	return strings.Join([]string{prefix, RandomString()}, string(sep))
 }
 
-// RandomString will generate a random string.
+// RandomString will generate a random string, from the DefaultRand.
 func RandomString() string {
-	suffix := make([]byte, randSuffixLen)
-
-	for i := range suffix {
-		suffix[i] = letterBytes[rand.Intn(len(letterBytes))]
-	}
-	return string(suffix)
+	return DefaultRand().RandomString()
 }
 
-// MakeRandomK8sName
+// MakeRandomK8sName will generate a random Kubernetes name that begins with
+// prefix.
 func MakeRandomK8sName(prefix string) string {
 	return MakeK8sNamePrefix(AppendRandomString(prefix))
 }
@@ -0,0 +1,186 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feature
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxIssuedStrings bounds the number of strings remembered to keep the strings
+// of a Rand unique, the oldest are forgotten first.
+const maxIssuedStrings = 1 << 16
+
+// Rand generates the random names of the framework from a seed, so that they
+// can be reproduced by running again with the same seed. It is safe for
+// concurrent use.
+//
+// The strings generated by a Rand and by all the Rand derived from it are
+// unique among the last issued ones, so that features running in parallel
+// can't get colliding names.
+type Rand struct {
+	seed   int64
+	issued *issuedStrings
+
+	mu      sync.Mutex
+	r       *rand.Rand
+	derived uint64
+	keys    map[string]uint64
+}
+
+type issuedStrings struct {
+	mu      sync.Mutex
+	strings map[string]struct{}
+	// order holds the strings in the order they were issued, as a ring
+	// buffer of at most maxIssuedStrings strings starting at next.
+	order []string
+	next  int
+}
+
+// add returns whether s wasn't issued yet and records it.
+func (i *issuedStrings) add(s string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if _, ok := i.strings[s]; ok {
+		return false
+	}
+	i.strings[s] = struct{}{}
+	if len(i.order) < maxIssuedStrings {
+		i.order = append(i.order, s)
+		return true
+	}
+	delete(i.strings, i.order[i.next])
+	i.order[i.next] = s
+	i.next = (i.next + 1) % maxIssuedStrings
+	return true
+}
+
+// NewRand creates a new Rand with the provided seed.
+func NewRand(seed int64) *Rand {
+	return &Rand{
+		seed:   seed,
+		issued: &issuedStrings{strings: make(map[string]struct{})},
+		r:      rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Seed returns the seed of r.
+func (r *Rand) Seed() int64 {
+	return r.seed
+}
+
+// Derive returns a new Rand, whose seed is derived from the seed of r and the
+// number of Rand derived from r before. The strings it generates are unique
+// among those of r and of all the Rand derived from r. Since the seed depends
+// on the call order, use DeriveFor when Rand are derived concurrently.
+func (r *Rand) Derive() *Rand {
+	r.mu.Lock()
+	r.derived++
+	seed := mixSeed(r.seed, r.derived)
+	r.mu.Unlock()
+
+	return r.derive(seed)
+}
+
+// DeriveFor returns a new Rand, whose seed is derived from the seed of r, the
+// key, such as a test or feature name, and the number of Rand derived from r
+// for the same key before. The seed doesn't depend on the order in which Rand
+// are derived for different keys. The strings it generates are unique among
+// those of r and of all the Rand derived from r.
+func (r *Rand) DeriveFor(key string) *Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	r.mu.Lock()
+	if r.keys == nil {
+		r.keys = make(map[string]uint64)
+	}
+	n := r.keys[key]
+	r.keys[key]++
+	r.mu.Unlock()
+
+	return r.derive(mixSeed(mixSeed(r.seed, h.Sum64()), n))
+}
+
+func (r *Rand) derive(seed int64) *Rand {
+	return &Rand{
+		seed:   seed,
+		issued: r.issued,
+		r:      rand.New(rand.NewSource(seed)),
+	}
+}
+
+// mixSeed mixes a seed with a sequence number, using the SplitMix64 finalizer,
+// so that consecutive sequence numbers give unrelated seeds.
+func mixSeed(seed int64, n uint64) int64 {
+	z := uint64(seed) + n*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// RandomString generates a random string, unique for r.
+func (r *Rand) RandomString() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	suffix := make([]byte, randSuffixLen)
+	for {
+		for i := range suffix {
+			suffix[i] = letterBytes[r.r.Intn(len(letterBytes))]
+		}
+		if s := string(suffix); r.issued.add(s) {
+			return s
+		}
+	}
+}
+
+// AppendRandomString generates a random string, unique for r, that begins
+// with prefix.
+func (r *Rand) AppendRandomString(prefix string) string {
+	return prefix + string(sep) + r.RandomString()
+}
+
+// MakeRandomK8sName generates a random Kubernetes name, unique for r, that
+// begins with prefix.
+func (r *Rand) MakeRandomK8sName(prefix string) string {
+	return MakeK8sNamePrefix(r.AppendRandomString(prefix))
+}
+
+var defaultRand atomic.Pointer[Rand]
+
+func init() {
+	// Properly seed the random number generator so RandomString() is actually random.
+	// Otherwise, rerunning tests will generate the same names for the test resources, causing conflicts with
+	// already existing resources.
+	defaultRand.Store(NewRand(time.Now().UTC().UnixNano()))
+}
+
+// DefaultRand returns the Rand used by RandomString, AppendRandomString and
+// MakeRandomK8sName, seeded from the wall clock unless set with SetDefaultRand.
+func DefaultRand() *Rand {
+	return defaultRand.Load()
+}
+
+// SetDefaultRand replaces the Rand used by RandomString, AppendRandomString
+// and MakeRandomK8sName, for the names to be reproducible.
+func SetDefaultRand(r *Rand) {
+	defaultRand.Store(r)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feature
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRandReproducible(t *testing.T) {
+	names := func() []string {
+		r := NewRand(42)
+		d1, d2 := r.Derive(), r.Derive()
+		return []string{
+			r.RandomString(),
+			d1.MakeRandomK8sName("test"),
+			d2.AppendRandomString("sink"),
+			d1.Derive().RandomString(),
+		}
+	}
+
+	first, second := names(), names()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("name %d: %q != %q", i, first[i], second[i])
+		}
+	}
+	if !strings.HasPrefix(first[1], "test-") || !strings.HasPrefix(first[2], "sink-") {
+		t.Errorf("unexpected names %v", first)
+	}
+	if other := NewRand(43).RandomString(); other == first[0] {
+		t.Errorf("seeds 42 and 43 generated the same string %q", other)
+	}
+}
+
+func TestRandUnique(t *testing.T) {
+	r := NewRand(1)
+
+	// Derived Rand generating the same stream must not issue the same strings.
+	a, b := r.Derive(), r.Derive()
+	b.r = NewRand(a.Seed()).r
+
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var wg sync.WaitGroup
+	for _, rr := range []*Rand{r, a, b} {
+		wg.Add(1)
+		go func(rr *Rand) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				s := rr.RandomString()
+				mu.Lock()
+				if seen[s] {
+					t.Errorf("%q issued twice", s)
+				}
+				seen[s] = true
+				mu.Unlock()
+			}
+		}(rr)
+	}
+	wg.Wait()
+}
+
+func TestRandFromContext(t *testing.T) {
+	if got := RandFromContext(context.Background()); got != DefaultRand() {
+		t.Errorf("expected the default Rand, got %v", got)
+	}
+	r := NewRand(1)
+	if got := RandFromContext(ContextWithRand(context.Background(), r)); got != r {
+		t.Errorf("expected the context Rand, got %v", got)
+	}
+}
+
+func TestRandDeriveFor(t *testing.T) {
+	names := func(keys ...string) map[string]string {
+		r := NewRand(42)
+		r.Derive()
+		names := make(map[string]string)
+		for _, k := range keys {
+			d := r.DeriveFor(k)
+			if _, ok := names[k]; ok {
+				k += "#2"
+			}
+			names[k] = d.RandomString()
+		}
+		return names
+	}
+
+	first := names("TestA", "TestB", "TestA")
+	second := names("TestB", "TestA", "TestA")
+	for k, v := range first {
+		if second[k] != v {
+			t.Errorf("%s: %q != %q", k, v, second[k])
+		}
+	}
+	if first["TestA"] == first["TestA#2"] {
+		t.Error("the same key derived the same Rand twice")
+	}
+}
+
+func TestIssuedStringsBounded(t *testing.T) {
+	i := &issuedStrings{strings: make(map[string]struct{})}
+	for n := 0; n < maxIssuedStrings+10; n++ {
+		if !i.add(strconv.Itoa(n)) {
+			t.Fatalf("%d issued twice", n)
+		}
+	}
+	if len(i.strings) != maxIssuedStrings {
+		t.Errorf("%d strings remembered, want %d", len(i.strings), maxIssuedStrings)
+	}
+	if i.add(strconv.Itoa(maxIssuedStrings + 9)) {
+		t.Error("a recent string was issued twice")
+	}
+	if !i.add("0") {
+		t.Error("the oldest string wasn't forgotten")
+	}
+}
@@ -0,0 +1,316 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feature
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"knative.dev/reconciler-test/pkg/state"
+)
+
+// Eventually returns a StepFn that runs fn every interval until it succeeds or
+// timeout passes. Failures of an attempt are only reported to the step T if it
+// is the last attempt, together with the history of the previous attempts.
+//
+// A zero interval or timeout is replaced by the poll timings found in the
+// context.
+func Eventually(fn StepFn, interval, timeout time.Duration) StepFn {
+	return func(ctx context.Context, t T) {
+		interval, timeout := retryTimings(ctx, interval, timeout)
+		deadline := time.Now().Add(timeout)
+
+		var history []string
+		for i := 1; ; i++ {
+			rt := runAttempt(ctx, t, fn)
+			if !rt.Failed() || rt.Skipped() {
+				rt.replay(t)
+				return
+			}
+			history = append(history, fmt.Sprintf("attempt %d: %s", i, rt.lastError()))
+
+			if time.Now().Add(interval).After(deadline) || !sleep(ctx, interval) {
+				t.Logf("Step did not succeed after %d attempts in %s:\n%s", i, timeout, strings.Join(history, "\n"))
+				rt.replay(t)
+				return
+			}
+			rt.cleanup()
+		}
+	}
+}
+
+// Consistently returns a StepFn that runs fn every interval for the given
+// duration, every attempt has to succeed. The first failed attempt is reported
+// to the step T together with the history of the previous attempts.
+//
+// A zero interval or duration is replaced by the poll timings found in the
+// context.
+func Consistently(fn StepFn, interval, duration time.Duration) StepFn {
+	return func(ctx context.Context, t T) {
+		interval, duration := retryTimings(ctx, interval, duration)
+		end := time.Now().Add(duration)
+
+		var history []string
+		for i := 1; ; i++ {
+			rt := runAttempt(ctx, t, fn)
+			if rt.Failed() {
+				history = append(history, fmt.Sprintf("attempt %d: %s", i, rt.lastError()))
+				t.Logf("Step failed at attempt %d:\n%s", i, strings.Join(history, "\n"))
+				rt.replay(t)
+				return
+			}
+			history = append(history, fmt.Sprintf("attempt %d: succeeded", i))
+
+			if rt.Skipped() || time.Now().Add(interval).After(end) {
+				rt.replay(t)
+				return
+			}
+			if !sleep(ctx, interval) {
+				rt.replay(t)
+				t.Errorf("Step interrupted after %d attempts: %v", i, ctx.Err())
+				return
+			}
+			rt.cleanup()
+		}
+	}
+}
+
+func retryTimings(ctx context.Context, interval, timeout time.Duration) (time.Duration, time.Duration) {
+	if interval == 0 || timeout == 0 {
+		defaultInterval, defaultTimeout := state.PollTimingsFromContext(ctx)
+		if interval == 0 {
+			interval = defaultInterval
+		}
+		if timeout == 0 {
+			timeout = defaultTimeout
+		}
+	}
+	return interval, timeout
+}
+
+// sleep waits for d, it returns false if the context is done before.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// runAttempt runs fn in its own goroutine, so that FailNow and SkipNow only
+// stop the attempt.
+func runAttempt(ctx context.Context, t T, fn StepFn) *recordingT {
+	rt := &recordingT{parent: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				rt.Errorf("Panic happened: '%v'", r)
+			}
+		}()
+		fn(ctx, rt)
+	}()
+	<-done
+	return rt
+}
+
+type recordKind int
+
+const (
+	recordLog recordKind = iota
+	recordError
+)
+
+type record struct {
+	kind recordKind
+	msg  string
+}
+
+// recordingT is a T that records what a single attempt reports, so that it can
+// be replayed on the step T.
+type recordingT struct {
+	parent T
+
+	mu       sync.Mutex
+	records  []record
+	failed   bool
+	failNow  bool
+	skipped  bool
+	cleanups []func()
+}
+
+var _ T = (*recordingT)(nil)
+
+func (r *recordingT) Name() string {
+	return r.parent.Name()
+}
+
+func (r *recordingT) Deadline() (time.Time, bool) {
+	return r.parent.Deadline()
+}
+
+func (r *recordingT) add(kind recordKind, msg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record{kind: kind, msg: msg})
+	if kind == recordError {
+		r.failed = true
+	}
+}
+
+func (r *recordingT) Log(args ...interface{}) {
+	r.add(recordLog, sprint(args...))
+}
+
+func (r *recordingT) Logf(format string, args ...interface{}) {
+	r.add(recordLog, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Error(args ...interface{}) {
+	r.add(recordError, sprint(args...))
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.add(recordError, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Fail() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed = true
+}
+
+func (r *recordingT) Fatal(args ...interface{}) {
+	r.Error(args...)
+	r.FailNow()
+}
+
+func (r *recordingT) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	r.FailNow()
+}
+
+func (r *recordingT) FailNow() {
+	r.mu.Lock()
+	r.failed = true
+	r.failNow = true
+	r.mu.Unlock()
+	runtime.Goexit()
+}
+
+func (r *recordingT) Skip(args ...interface{}) {
+	r.Log(args...)
+	r.SkipNow()
+}
+
+func (r *recordingT) Skipf(format string, args ...interface{}) {
+	r.Logf(format, args...)
+	r.SkipNow()
+}
+
+func (r *recordingT) SkipNow() {
+	r.mu.Lock()
+	r.skipped = true
+	r.mu.Unlock()
+	runtime.Goexit()
+}
+
+func (r *recordingT) Failed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failed
+}
+
+func (r *recordingT) Skipped() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.skipped
+}
+
+func (r *recordingT) Cleanup(f func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cleanups = append(r.cleanups, f)
+}
+
+// lastError returns the last error reported by the attempt.
+func (r *recordingT) lastError() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.records) - 1; i >= 0; i-- {
+		if r.records[i].kind == recordError {
+			return r.records[i].msg
+		}
+	}
+	return "failed"
+}
+
+// cleanup runs the cleanup functions registered by an attempt that is not
+// replayed.
+func (r *recordingT) cleanup() {
+	r.mu.Lock()
+	cleanups := r.cleanups
+	r.cleanups = nil
+	r.mu.Unlock()
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
+
+// replay reports what the attempt recorded to t.
+func (r *recordingT) replay(t T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range r.cleanups {
+		t.Cleanup(c)
+	}
+	for _, rec := range r.records {
+		switch rec.kind {
+		case recordLog:
+			t.Log(rec.msg)
+		case recordError:
+			t.Error(rec.msg)
+		}
+	}
+	switch {
+	case r.failNow:
+		t.FailNow()
+	case r.skipped:
+		// The attempt might have failed before being skipped.
+		if r.failed {
+			t.Fail()
+		}
+		t.SkipNow()
+	case r.failed:
+		t.Fail()
+	}
+}
+
+// sprint formats args like testing.T.Log does.
+func sprint(args ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
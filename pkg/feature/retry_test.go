@@ -0,0 +1,116 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feature
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"knative.dev/reconciler-test/pkg/state"
+)
+
+func TestEventuallySucceeds(t *testing.T) {
+	var attempts int32
+	fn := Eventually(func(ctx context.Context, t T) {
+		if n := atomic.AddInt32(&attempts, 1); n < 3 {
+			t.Fatalf("attempt %d not ready", n)
+		}
+		t.Log("ready")
+	}, time.Millisecond, time.Second)
+
+	rt := runAttempt(context.Background(), t, fn)
+
+	require.False(t, rt.Failed())
+	require.EqualValues(t, 3, attempts)
+	require.Equal(t, []record{{kind: recordLog, msg: "ready"}}, rt.records)
+}
+
+func TestEventuallyFails(t *testing.T) {
+	var attempts int32
+	fn := Eventually(func(ctx context.Context, t T) {
+		t.Errorf("attempt %d not ready", atomic.AddInt32(&attempts, 1))
+	}, 0, 0)
+
+	ctx := state.ContextWithPollTimings(context.Background(), 10*time.Millisecond, 35*time.Millisecond)
+	rt := runAttempt(ctx, t, fn)
+
+	n := atomic.LoadInt32(&attempts)
+	require.True(t, rt.Failed())
+	require.GreaterOrEqual(t, n, int32(2))
+	require.Len(t, rt.records, 2)
+	history := rt.records[0].msg
+	require.True(t, strings.HasPrefix(history, fmt.Sprintf("Step did not succeed after %d attempts in 35ms:\n", n)), history)
+	for i := int32(1); i <= n; i++ {
+		require.Contains(t, history, fmt.Sprintf("attempt %d: attempt %d not ready", i, i))
+	}
+	// Only the last attempt is reported as a failure.
+	require.Equal(t, record{kind: recordError, msg: fmt.Sprintf("attempt %d not ready", n)}, rt.records[1])
+}
+
+func TestEventuallySkipsAfterFailure(t *testing.T) {
+	var attempts int32
+	fn := Eventually(func(ctx context.Context, t T) {
+		atomic.AddInt32(&attempts, 1)
+		t.Error("broker not found")
+		t.Skip("brokers are not supported")
+	}, time.Millisecond, time.Second)
+
+	rt := runAttempt(context.Background(), t, fn)
+
+	require.EqualValues(t, 1, attempts)
+	require.True(t, rt.Failed())
+	require.True(t, rt.Skipped())
+}
+
+func TestConsistently(t *testing.T) {
+	var attempts int32
+	fn := Consistently(func(ctx context.Context, t T) {
+		atomic.AddInt32(&attempts, 1)
+	}, 5*time.Millisecond, 30*time.Millisecond)
+
+	rt := runAttempt(context.Background(), t, fn)
+
+	require.False(t, rt.Failed())
+	require.GreaterOrEqual(t, atomic.LoadInt32(&attempts), int32(3))
+}
+
+func TestConsistentlyFails(t *testing.T) {
+	var attempts int32
+	var cleanups int32
+	fn := Consistently(func(ctx context.Context, t T) {
+		t.Cleanup(func() { atomic.AddInt32(&cleanups, 1) })
+		if atomic.AddInt32(&attempts, 1) == 2 {
+			t.Fatal("broken")
+		}
+	}, time.Millisecond, time.Second)
+
+	rt := runAttempt(context.Background(), t, fn)
+
+	require.True(t, rt.Failed())
+	require.EqualValues(t, 2, attempts)
+	// The cleanup of the first attempt ran, the one of the failed attempt is
+	// passed to the step T.
+	require.EqualValues(t, 1, cleanups)
+	require.Len(t, rt.cleanups, 1)
+	require.Equal(t, "Step failed at attempt 2:\nattempt 1: succeeded\nattempt 2: broken", rt.records[0].msg)
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feature
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Tags adds free-form tags to the feature, for example the capabilities it
+// exercises, that can be used to select features with a TagExpression.
+func (f *Feature) Tags(tags ...string) {
+	f.tags = append(f.tags, tags...)
+}
+
+// GetTags returns the tags of the feature.
+func (f *Feature) GetTags() []string {
+	return f.tags
+}
+
+// TagExpression is a boolean expression over feature tags, for example
+// `tls && !slow` or `(tls || oidc) && !flaky`.
+//
+// The supported operators are `!` (not), `&&` (and), `||` (or) and
+// parenthesis, tags are made of letters, digits and `-_.:/` characters.
+type TagExpression struct {
+	expr string
+	root tagNode
+}
+
+// ParseTagExpression parses the given expression, an empty expression matches
+// any feature.
+func ParseTagExpression(expr string) (*TagExpression, error) {
+	p := &tagParser{tokens: tokenizeTags(expr)}
+	if len(p.tokens) == 0 {
+		return &TagExpression{expr: expr}, nil
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag expression %q: %w", expr, err)
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("invalid tag expression %q: unexpected %q", expr, tok)
+	}
+	return &TagExpression{expr: expr, root: root}, nil
+}
+
+// MustParseTagExpression is like ParseTagExpression but panics if the
+// expression can't be parsed.
+func MustParseTagExpression(expr string) *TagExpression {
+	e, err := ParseTagExpression(expr)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Matches returns whether the given tags satisfy the expression.
+func (e *TagExpression) Matches(tags []string) bool {
+	if e == nil || e.root == nil {
+		return true
+	}
+	set := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		set[t] = true
+	}
+	return e.root.eval(set)
+}
+
+func (e *TagExpression) String() string {
+	if e == nil {
+		return ""
+	}
+	return e.expr
+}
+
+type tagNode interface {
+	eval(tags map[string]bool) bool
+}
+
+type tagLeaf string
+
+func (n tagLeaf) eval(tags map[string]bool) bool { return tags[string(n)] }
+
+type tagNot struct{ n tagNode }
+
+func (n tagNot) eval(tags map[string]bool) bool { return !n.n.eval(tags) }
+
+type tagAnd struct{ l, r tagNode }
+
+func (n tagAnd) eval(tags map[string]bool) bool { return n.l.eval(tags) && n.r.eval(tags) }
+
+type tagOr struct{ l, r tagNode }
+
+func (n tagOr) eval(tags map[string]bool) bool { return n.l.eval(tags) || n.r.eval(tags) }
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_.:/", r)
+}
+
+func tokenizeTags(expr string) []string {
+	var tokens []string
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '!' || r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		case (r == '&' || r == '|') && i+1 < len(runes) && runes[i+1] == r:
+			tokens = append(tokens, string(runes[i:i+2]))
+			i += 2
+		case isTagRune(r):
+			j := i
+			for j < len(runes) && isTagRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		default:
+			// Let the parser report the invalid character.
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+	return tokens
+}
+
+type tagParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *tagParser) peek() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *tagParser) parseOr() (tagNode, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if tok, _ := p.peek(); tok != "||" {
+			return l, nil
+		}
+		p.pos++
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = tagOr{l: l, r: r}
+	}
+}
+
+func (p *tagParser) parseAnd() (tagNode, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if tok, _ := p.peek(); tok != "&&" {
+			return l, nil
+		}
+		p.pos++
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = tagAnd{l: l, r: r}
+	}
+}
+
+func (p *tagParser) parseUnary() (tagNode, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+	switch {
+	case tok == "!":
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return tagNot{n: n}, nil
+	case tok == "(":
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok, _ := p.peek(); tok != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return n, nil
+	case isTagRune([]rune(tok)[0]):
+		return tagLeaf(tok), nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+}
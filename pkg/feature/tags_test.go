@@ -0,0 +1,75 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feature
+
+import (
+	"testing"
+)
+
+func TestTagExpression(t *testing.T) {
+	tests := []struct {
+		expr string
+		tags []string
+		want bool
+	}{
+		{expr: "", tags: nil, want: true},
+		{expr: "  ", tags: []string{"tls"}, want: true},
+		{expr: "tls", tags: []string{"tls", "oidc"}, want: true},
+		{expr: "tls", tags: []string{"oidc"}, want: false},
+		{expr: "tls && !slow", tags: []string{"tls"}, want: true},
+		{expr: "tls && !slow", tags: []string{"tls", "slow"}, want: false},
+		{expr: "tls || oidc", tags: []string{"oidc"}, want: true},
+		{expr: "!tls", tags: nil, want: true},
+		{expr: "!!tls", tags: []string{"tls"}, want: true},
+		// && binds tighter than ||.
+		{expr: "a || b && c", tags: []string{"a"}, want: true},
+		{expr: "(a || b) && c", tags: []string{"a"}, want: false},
+		{expr: "(a || b) && c", tags: []string{"b", "c"}, want: true},
+		{expr: "eventing.knative.dev/broker && k8s-1.28", tags: []string{"eventing.knative.dev/broker", "k8s-1.28"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := ParseTagExpression(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := e.Matches(tt.tags); got != tt.want {
+				t.Errorf("%q.Matches(%q) = %v, want %v", tt.expr, tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagExpressionErrors(t *testing.T) {
+	for _, expr := range []string{"tls &&", "(tls", "tls)", "tls & oidc", "tls oidc", "&& tls", "!"} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseTagExpression(expr); err == nil {
+				t.Errorf("expected an error parsing %q", expr)
+			}
+		})
+	}
+}
+
+func TestFeatureTags(t *testing.T) {
+	f := NewFeature()
+	f.Tags("tls", "oidc")
+	f.Tags("slow")
+
+	if !MustParseTagExpression("tls && oidc && slow").Matches(f.GetTags()) {
+		t.Errorf("unexpected tags %q", f.GetTags())
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feature
+
+// Upgrade is a feature spanning an upgrade of the system under test. Its
+// phases run in order: PreUpgrade, the start of the continual probes, the
+// upgrade itself, PostUpgrade and the verification of the continual probes.
+// All the phases share the test namespace, the references and the
+// state.Store.
+type Upgrade struct {
+	Name string
+	// PreUpgrade creates resources before the upgrade and asserts them.
+	PreUpgrade *Feature
+	// PostUpgrade asserts, after the upgrade, the resources created before
+	// it.
+	PostUpgrade *Feature
+
+	continualStart  *Feature
+	continualVerify *Feature
+}
+
+// NewUpgrade creates a new upgrade feature with the provided name.
+func NewUpgrade(name string) *Upgrade {
+	return &Upgrade{
+		Name:            name,
+		PreUpgrade:      NewFeatureNamed(name + " PreUpgrade"),
+		PostUpgrade:     NewFeatureNamed(name + " PostUpgrade"),
+		continualStart:  NewFeatureNamed(name + " ContinualDuringUpgrade"),
+		continualVerify: NewFeatureNamed(name + " ContinualDuringUpgrade Verify"),
+	}
+}
+
+// ContinualDuringUpgrade adds a probe running throughout the upgrade. The start
+// step runs after the PreUpgrade steps, before the upgrade, and the verify step
+// asserts what the probe collected after the PostUpgrade steps.
+//
+// When the phases run in separate test invocations, the probe has to outlive
+// the process that started it, for example an eventshub sender, and the
+// information needed to verify it has to be saved in the state.Store.
+func (u *Upgrade) ContinualDuringUpgrade(name string, start, verify StepFn, opts ...StepOption) {
+	u.continualStart.Setup(name, start, opts...)
+	u.continualVerify.Assert(name, verify, opts...)
+}
+
+// BeforeUpgrade returns the features run before the upgrade, PreUpgrade then
+// the start of the continual probes.
+func (u *Upgrade) BeforeUpgrade() []*Feature {
+	return []*Feature{u.PreUpgrade, u.continualStart}
+}
+
+// AfterUpgrade returns the features run after the upgrade, PostUpgrade then
+// the verification of the continual probes.
+func (u *Upgrade) AfterUpgrade() []*Feature {
+	return []*Feature{u.PostUpgrade, u.continualVerify}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package k8sevents holds the helpers for Kubernetes events shared by the
+// emitters and the environment.
+package k8sevents
+
+import (
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SortByTime sorts the events in place from the oldest to the most recent one
+// and returns them.
+func SortByTime(items []corev1.Event) []corev1.Event {
+	sort.SliceStable(items, func(i, j int) bool {
+		return eventTime(items[i]).Before(eventTime(items[j]))
+	})
+	return items
+}
+
+func eventTime(e corev1.Event) time.Time {
+	// Some events might not contain last timestamp, in that case
+	// we fall back to the event time.
+	if e.LastTimestamp.Time.IsZero() {
+		return e.EventTime.Time
+	}
+	return e.LastTimestamp.Time
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sevents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSortByTime(t *testing.T) {
+	now := time.Now()
+	event := func(name string, last time.Time, eventTime time.Time) corev1.Event {
+		return corev1.Event{
+			ObjectMeta:    metav1.ObjectMeta{Name: name},
+			LastTimestamp: metav1.NewTime(last),
+			EventTime:     metav1.NewMicroTime(eventTime),
+		}
+	}
+
+	events := SortByTime([]corev1.Event{
+		event("last", now.Add(time.Minute), time.Time{}),
+		// Without last timestamp, the event time is used.
+		event("first", time.Time{}, now.Add(-time.Minute)),
+		event("middle", now, time.Time{}),
+	})
+
+	names := make([]string, 0, len(events))
+	for _, e := range events {
+		names = append(names, e.Name)
+	}
+	require.Equal(t, []string{"first", "middle", "last"}, names)
+}
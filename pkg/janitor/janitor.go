@@ -0,0 +1,276 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package janitor deletes the test namespaces left behind by failed runs once
+// they expired, along with the cluster-scoped resources referencing them.
+package janitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"knative.dev/reconciler-test/pkg/environment"
+)
+
+// Options configures which namespaces are expired.
+type Options struct {
+	// OlderThan, when set, expires the namespaces older than it, ignoring
+	// their TTL.
+	OlderThan time.Duration
+	// DefaultTTL is the TTL of the namespaces without the TTL annotation,
+	// created before the namespaces were stamped.
+	DefaultTTL time.Duration
+	// DryRun reports what would be deleted without deleting anything.
+	DryRun bool
+	// Now returns the current time, time.Now when not set.
+	Now func() time.Time
+}
+
+// Janitor deletes the expired test namespaces, the namespaces with the
+// reconciler-test labels, and the cluster-scoped resources with the
+// reconciler-test labels that reference them.
+type Janitor struct {
+	kube    kubernetes.Interface
+	dynamic dynamic.Interface
+	opts    Options
+}
+
+// New creates a Janitor.
+func New(kube kubernetes.Interface, dynamic dynamic.Interface, opts Options) *Janitor {
+	if opts.DefaultTTL <= 0 {
+		opts.DefaultTTL = environment.DefaultNamespaceTTL
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Janitor{kube: kube, dynamic: dynamic, opts: opts}
+}
+
+// Report lists the resources deleted, or that would be deleted in dry-run
+// mode, and the errors met on the way.
+type Report struct {
+	DryRun         bool       `json:"dryRun"`
+	Namespaces     []Deletion `json:"namespaces"`
+	ClusterObjects []Deletion `json:"clusterObjects"`
+	Errors         []string   `json:"errors,omitempty"`
+}
+
+// Deletion is a resource deleted by the janitor.
+type Deletion struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	// Namespace is the expired namespace, referenced by the cluster-scoped
+	// resources.
+	Namespace string `json:"namespace,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// WriteText writes the report as one line per resource.
+func (r *Report) WriteText(w io.Writer) error {
+	verb := "deleted"
+	if r.DryRun {
+		verb = "would delete"
+	}
+	var sb strings.Builder
+	for _, d := range r.Namespaces {
+		fmt.Fprintf(&sb, "%s namespace %s: %s\n", verb, d.Name, d.Reason)
+	}
+	for _, d := range r.ClusterObjects {
+		fmt.Fprintf(&sb, "%s %s %s %s: %s\n", verb, d.APIVersion, d.Kind, d.Name, d.Reason)
+	}
+	for _, err := range r.Errors {
+		fmt.Fprintf(&sb, "error: %s\n", err)
+	}
+	if len(r.Namespaces) == 0 && len(r.ClusterObjects) == 0 {
+		sb.WriteString("no expired test namespaces\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Run deletes the expired namespaces and the cluster-scoped resources
+// referencing them, the cluster-scoped resources first.
+func (j *Janitor) Run(ctx context.Context) (*Report, error) {
+	report := &Report{DryRun: j.opts.DryRun, Namespaces: []Deletion{}, ClusterObjects: []Deletion{}}
+
+	namespaces, err := j.kube.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: environment.TestLabelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the test namespaces: %w", err)
+	}
+	expired := sets.New[string]()
+	for _, ns := range namespaces.Items {
+		if ns.DeletionTimestamp != nil {
+			continue
+		}
+		if reason, ok := j.expired(&ns); ok {
+			expired.Insert(ns.Name)
+			report.Namespaces = append(report.Namespaces, Deletion{APIVersion: "v1", Kind: "Namespace", Name: ns.Name, Reason: reason})
+		}
+	}
+	if expired.Len() == 0 {
+		return report, nil
+	}
+
+	objs, err := j.referencing(ctx, expired)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		d := Deletion{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+			Namespace:  obj.namespace,
+			Reason:     "references namespace " + obj.namespace,
+		}
+		if !j.opts.DryRun {
+			if err := j.dynamic.Resource(obj.gvr).Delete(ctx, obj.GetName(), metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				report.Errors = append(report.Errors, fmt.Sprintf("failed to delete %s %s: %v", d.Kind, d.Name, err))
+				continue
+			}
+		}
+		report.ClusterObjects = append(report.ClusterObjects, d)
+	}
+
+	if !j.opts.DryRun {
+		deleted := report.Namespaces[:0]
+		for _, d := range report.Namespaces {
+			if err := j.kube.CoreV1().Namespaces().Delete(ctx, d.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				report.Errors = append(report.Errors, fmt.Sprintf("failed to delete namespace %s: %v", d.Name, err))
+				continue
+			}
+			deleted = append(deleted, d)
+		}
+		report.Namespaces = deleted
+	}
+	return report, nil
+}
+
+// expired returns whether the namespace expired and why.
+func (j *Janitor) expired(ns *corev1.Namespace) (string, bool) {
+	created := ns.CreationTimestamp.Time
+	if at, err := time.Parse(time.RFC3339, ns.Annotations[environment.NamespaceCreatedAtAnnotation]); err == nil {
+		created = at
+	}
+	age := j.opts.Now().Sub(created).Round(time.Second)
+
+	if j.opts.OlderThan > 0 {
+		return fmt.Sprintf("age %s, older than %s", age, j.opts.OlderThan), age >= j.opts.OlderThan
+	}
+	ttl := j.opts.DefaultTTL
+	if d, err := time.ParseDuration(ns.Annotations[environment.NamespaceTTLAnnotation]); err == nil {
+		ttl = d
+	}
+	return fmt.Sprintf("age %s, TTL %s", age, ttl), age >= ttl
+}
+
+type clusterObject struct {
+	*unstructured.Unstructured
+	gvr schema.GroupVersionResource
+	// namespace is the expired namespace referenced by the object.
+	namespace string
+}
+
+// referencing returns the cluster-scoped resources with the reconciler-test
+// labels that reference one of the namespaces in a namespace field, for
+// example the subjects of a ClusterRoleBinding.
+func (j *Janitor) referencing(ctx context.Context, namespaces sets.Set[string]) ([]clusterObject, error) {
+	lists, err := discovery.ServerPreferredResources(j.kube.Discovery())
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("failed to discover the resources: %w", err)
+	}
+
+	var objs []clusterObject
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if r.Namespaced || strings.Contains(r.Name, "/") || r.Name == "namespaces" ||
+				!sets.New(r.Verbs...).HasAll("list", "delete") {
+				continue
+			}
+			gvr := gv.WithResource(r.Name)
+			l, err := j.dynamic.Resource(gvr).List(ctx, metav1.ListOptions{LabelSelector: environment.TestLabelSelector})
+			if err != nil {
+				if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) || apierrors.IsMethodNotSupported(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to list %s: %w", gvr, err)
+			}
+			for i := range l.Items {
+				obj := &l.Items[i]
+				// Skip the metadata, cluster-scoped resources don't have a
+				// namespace there.
+				content := obj.UnstructuredContent()
+				for k, v := range content {
+					if k == "metadata" {
+						continue
+					}
+					if ns, ok := findNamespace(v, namespaces); ok {
+						objs = append(objs, clusterObject{Unstructured: obj, gvr: gvr, namespace: ns})
+						break
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(objs, func(i, j int) bool {
+		if objs[i].gvr.String() != objs[j].gvr.String() {
+			return objs[i].gvr.String() < objs[j].gvr.String()
+		}
+		return objs[i].GetName() < objs[j].GetName()
+	})
+	return objs, nil
+}
+
+// findNamespace looks for a namespace field set to one of the namespaces.
+func findNamespace(v interface{}, namespaces sets.Set[string]) (string, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if ns, ok := v["namespace"].(string); ok && namespaces.Has(ns) {
+			return ns, true
+		}
+		for _, f := range v {
+			if ns, ok := findNamespace(f, namespaces); ok {
+				return ns, true
+			}
+		}
+	case []interface{}:
+		for _, f := range v {
+			if ns, ok := findNamespace(f, namespaces); ok {
+				return ns, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package janitor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"knative.dev/reconciler-test/pkg/environment"
+)
+
+var (
+	now = time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	clusterRoleBindings = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"}
+
+	testLabels = map[string]string{
+		"app.kubernetes.io/component": "reconciler-test",
+		"app.kubernetes.io/name":      "reconciler-test",
+	}
+)
+
+func namespace(name string, created time.Time, ttl string, labels map[string]string) *corev1.Namespace {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:              name,
+		Labels:            labels,
+		CreationTimestamp: metav1.NewTime(created),
+	}}
+	if ttl != "" {
+		ns.Annotations = map[string]string{
+			environment.NamespaceCreatedAtAnnotation: created.Format(time.RFC3339),
+			environment.NamespaceTTLAnnotation:       ttl,
+		}
+	}
+	return ns
+}
+
+// reusedNamespace returns a namespace of a namespace pool created at created,
+// and stamped again when it was handed out once more at reused.
+func reusedNamespace(name string, created, reused time.Time, ttl string) *corev1.Namespace {
+	ns := namespace(name, created, ttl, testLabels)
+	ns.Annotations[environment.NamespaceCreatedAtAnnotation] = reused.Format(time.RFC3339)
+	return ns
+}
+
+func binding(name, namespace string, labels map[string]string) runtime.Object {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "ClusterRoleBinding",
+		"metadata":   map[string]interface{}{"name": name},
+		"subjects": []interface{}{
+			map[string]interface{}{"kind": "ServiceAccount", "name": "default", "namespace": namespace},
+		},
+	}}
+	u.SetLabels(labels)
+	return u
+}
+
+func TestJanitor(t *testing.T) {
+	tests := map[string]struct {
+		opts           Options
+		namespaces     []string
+		clusterObjects []string
+		remaining      []string
+		bindings       int
+	}{
+		"expired": {
+			namespaces:     []string{"test-expired", "test-legacy", "test-short-ttl"},
+			clusterObjects: []string{"expired-binding"},
+			remaining:      []string{"test-fresh", "test-reused", "unlabeled"},
+			bindings:       2,
+		},
+		"dry run": {
+			opts:           Options{DryRun: true},
+			namespaces:     []string{"test-expired", "test-legacy", "test-short-ttl"},
+			clusterObjects: []string{"expired-binding"},
+			remaining:      []string{"test-expired", "test-fresh", "test-legacy", "test-reused", "test-short-ttl", "unlabeled"},
+			bindings:       3,
+		},
+		"older than": {
+			opts:           Options{OlderThan: 3 * time.Hour},
+			namespaces:     []string{"test-expired", "test-legacy"},
+			clusterObjects: []string{"expired-binding"},
+			remaining:      []string{"test-fresh", "test-reused", "test-short-ttl", "unlabeled"},
+			bindings:       2,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			kube := fake.NewSimpleClientset(
+				namespace("test-expired", now.Add(-25*time.Hour), "24h0m0s", testLabels),
+				namespace("test-fresh", now.Add(-time.Hour), "24h0m0s", testLabels),
+				namespace("test-short-ttl", now.Add(-2*time.Hour), "1h0m0s", testLabels),
+				namespace("test-legacy", now.Add(-30*time.Hour), "", testLabels),
+				namespace("unlabeled", now.Add(-100*time.Hour), "", nil),
+				reusedNamespace("test-reused", now.Add(-30*time.Hour), now.Add(-time.Hour), "24h0m0s"),
+			)
+			kube.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+				GroupVersion: "rbac.authorization.k8s.io/v1",
+				APIResources: []metav1.APIResource{
+					{Name: "clusterrolebindings", Kind: "ClusterRoleBinding", Verbs: []string{"list", "delete"}},
+				},
+			}}
+			dc := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{clusterRoleBindings: "ClusterRoleBindingList"},
+				binding("expired-binding", "test-expired", testLabels),
+				binding("fresh-binding", "test-fresh", testLabels),
+				binding("unlabeled-binding", "test-expired", nil),
+			)
+
+			tc.opts.Now = func() time.Time { return now }
+			report, err := New(kube, dc, tc.opts).Run(context.Background())
+			require.NoError(t, err)
+			require.Empty(t, report.Errors)
+
+			var namespaces []string
+			for _, d := range report.Namespaces {
+				namespaces = append(namespaces, d.Name)
+			}
+			require.ElementsMatch(t, tc.namespaces, namespaces)
+			var clusterObjects []string
+			for _, d := range report.ClusterObjects {
+				clusterObjects = append(clusterObjects, d.Name)
+				require.Equal(t, "test-expired", d.Namespace)
+			}
+			require.Equal(t, tc.clusterObjects, clusterObjects)
+
+			nss, err := kube.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+			require.NoError(t, err)
+			var remaining []string
+			for _, ns := range nss.Items {
+				remaining = append(remaining, ns.Name)
+			}
+			require.ElementsMatch(t, tc.remaining, remaining)
+
+			bindings, err := dc.Resource(clusterRoleBindings).List(context.Background(), metav1.ListOptions{})
+			require.NoError(t, err)
+			require.Len(t, bindings.Items, tc.bindings)
+		})
+	}
+}
+
+func TestReportWriteText(t *testing.T) {
+	report := &Report{
+		DryRun:         true,
+		Namespaces:     []Deletion{{APIVersion: "v1", Kind: "Namespace", Name: "test-a", Reason: "age 25h0m0s, TTL 24h0m0s"}},
+		ClusterObjects: []Deletion{{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding", Name: "b", Namespace: "test-a", Reason: "references namespace test-a"}},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteText(&buf))
+	require.Equal(t, `would delete namespace test-a: age 25h0m0s, TTL 24h0m0s
+would delete rbac.authorization.k8s.io/v1 ClusterRoleBinding b: references namespace test-a
+`, buf.String())
+}
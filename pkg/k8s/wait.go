@@ -340,7 +340,7 @@ func WaitForServiceReady(ctx context.Context, t feature.T, name string, readines
 	ns := env.Namespace()
 	jobs := kubeclient.Get(ctx).BatchV1().Jobs(ns)
 	label := "readiness-check"
-	jobName := feature.MakeRandomK8sName(name + "-" + label)
+	jobName := feature.RandFromContext(ctx).MakeRandomK8sName(name + "-" + label)
 	sinkURI := apis.HTTP(fmt.Sprintf("%s.%s.svc", name, ns))
 	sinkURI.Path = readinessPath
 	curl := fmt.Sprintf("curl --max-time 2 "+
@@ -0,0 +1,199 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knative
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/state"
+)
+
+// ConfigMapOption configures PatchConfigMap.
+type ConfigMapOption func(*configMapOptions)
+
+type configMapOptions struct {
+	ready wait.ConditionWithContextFunc
+}
+
+// WithConfigMapReadiness sets the condition polled after patching and after
+// restoring the ConfigMap, until the components watching it picked up the
+// change, for example by checking that a webhook accepts a resource that is
+// only valid with a feature flag enabled.
+func WithConfigMapReadiness(ready wait.ConditionWithContextFunc) ConfigMapOption {
+	return func(o *configMapOptions) {
+		o.ready = ready
+	}
+}
+
+// PatchConfigMap returns a step applying the JSON merge patch to the ConfigMap
+// name of the Knative namespace, set with WithKnativeNamespace, for example:
+//
+//	f.Setup("enable OIDC", knative.PatchConfigMap("config-features",
+//		[]byte(`{"data":{"authentication-oidc":"enabled"}}`)))
+//
+// The step records the original labels, data and binary data of the ConfigMap
+// in the state of the feature and defers a Teardown step restoring them, which
+// runs even when the feature fails. Without WithConfigMapReadiness, the step waits one poll
+// interval for the change to propagate.
+//
+// Changes to the ConfigMap are cluster-wide, so the feature holds a Lease
+// named after the ConfigMap in the Knative namespace from the patch until the
+// restore, the other features patching the ConfigMap, in this process or in
+// another one, wait for it up to the poll timeout. The same feature can patch
+// the ConfigMap several times, it is restored to its content before the first
+// patch.
+func PatchConfigMap(name string, patch []byte, opts ...ConfigMapOption) feature.StepFn {
+	o := &configMapOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(ctx context.Context, t feature.T) {
+		f := feature.FromContext(ctx)
+		if f == nil {
+			t.Fatalf("PatchConfigMap has to run as a step of a feature")
+		}
+		namespace := KnativeNamespaceFromContext(ctx)
+		client := kubeclient.Get(ctx)
+		interval, timeout := environment.PollTimingsFromContext(ctx)
+
+		lease, acquired, err := acquireLease(ctx, client, f, namespace, "rekt-configmap-"+name, interval, timeout, t.Logf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lease.mu.Lock()
+		defer lease.mu.Unlock()
+
+		key := configMapStateKey(namespace, name)
+		if acquired {
+			// Deferred first, to release the lease when the patch fails.
+			f.DeferTeardown(fmt.Sprintf("restore ConfigMap %s/%s", namespace, name), restoreConfigMap(lease, name, key, o))
+
+			cm, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Failed to get ConfigMap %s/%s: %v", namespace, name, err)
+			}
+			if err := state.Set(ctx, key, configMapContent{Labels: cm.Labels, Data: cm.Data, BinaryData: cm.BinaryData}); err != nil {
+				t.Fatalf("Failed to record the data of ConfigMap %s/%s: %v", namespace, name, err)
+			}
+		}
+
+		if _, err := client.CoreV1().ConfigMaps(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			t.Fatalf("Failed to patch ConfigMap %s/%s: %v", namespace, name, err)
+		}
+		t.Logf("Patched ConfigMap %s/%s with %s", namespace, name, patch)
+		waitForConfigMap(ctx, t, namespace, name, o)
+	}
+}
+
+// restoreConfigMap restores the content of the ConfigMap recorded by
+// PatchConfigMap and releases the lease.
+func restoreConfigMap(lease *configMapLease, name, key string, o *configMapOptions) feature.StepFn {
+	return func(ctx context.Context, t feature.T) {
+		defer func() {
+			if err := lease.release(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+
+		// The patch step failed before recording the data.
+		if !hasStateKey(ctx, key) {
+			return
+		}
+		content := state.MustGet[configMapContent](ctx, t, key)
+
+		cms := kubeclient.Get(ctx).CoreV1().ConfigMaps(lease.namespace)
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			cm, err := cms.Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			cm.Labels = content.Labels
+			cm.Data = content.Data
+			cm.BinaryData = content.BinaryData
+			_, err = cms.Update(ctx, cm, metav1.UpdateOptions{})
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Failed to restore ConfigMap %s/%s: %v", lease.namespace, name, err)
+		}
+		if deleter, ok := state.FromContext(ctx).(state.Deleter); ok {
+			if err := deleter.Delete(ctx, key); err != nil {
+				t.Error(err)
+			}
+		}
+		t.Logf("Restored ConfigMap %s/%s", lease.namespace, name)
+		waitForConfigMap(ctx, t, lease.namespace, name, o)
+	}
+}
+
+// configMapContent is what PatchConfigMap records of a ConfigMap to restore it.
+type configMapContent struct {
+	Labels     map[string]string `json:"labels,omitempty"`
+	Data       map[string]string `json:"data,omitempty"`
+	BinaryData map[string][]byte `json:"binaryData,omitempty"`
+}
+
+// configMapStateKey is the key of the state holding the original content of
+// the ConfigMap.
+func configMapStateKey(namespace, name string) string {
+	return "knative/configmap/" + namespace + "/" + name
+}
+
+// hasStateKey returns whether the state store holds the key, the stores that
+// can't list their keys are asked for its value.
+func hasStateKey(ctx context.Context, key string) bool {
+	store := state.FromContext(ctx)
+	lister, ok := store.(state.KeyLister)
+	if !ok {
+		var v json.RawMessage
+		return store.Get(ctx, key, &v) == nil
+	}
+	for _, k := range lister.Keys(ctx) {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForConfigMap waits for the components watching the ConfigMap to pick up
+// the change.
+func waitForConfigMap(ctx context.Context, t feature.T, namespace, name string, o *configMapOptions) {
+	interval, timeout := environment.PollTimingsFromContext(ctx)
+	if o.ready == nil {
+		select {
+		case <-ctx.Done():
+		case <-time.After(interval):
+		}
+		return
+	}
+	if err := wait.PollUntilContextTimeout(ctx, interval, timeout, true, o.ready); err != nil {
+		t.Fatalf("Change to ConfigMap %s/%s not picked up: %v", namespace, name, err)
+	}
+}
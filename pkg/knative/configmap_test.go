@@ -0,0 +1,135 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knative
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/ptr"
+
+	"knative.dev/reconciler-test/pkg/environment"
+	"knative.dev/reconciler-test/pkg/environment/fakeenv"
+	"knative.dev/reconciler-test/pkg/feature"
+	testlog "knative.dev/reconciler-test/pkg/logging"
+	"knative.dev/reconciler-test/pkg/state"
+)
+
+func TestPatchConfigMap(t *testing.T) {
+	global := fakeenv.NewGlobalEnvironment(testlog.WithTestLogger(context.Background(), t),
+		fakeenv.WithKubeObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "knative-eventing", Name: "config-features", Labels: map[string]string{"app": "eventing"}},
+			Data:       map[string]string{"authentication-oidc": "disabled", "kreference-group": "disabled"},
+			BinaryData: map[string][]byte{"ca.crt": []byte("original")},
+		}),
+	)
+	ctx, env := global.Environment(environment.Managed(t), WithKnativeNamespace("knative-eventing"))
+
+	readinessChecks := 0
+	f := feature.NewFeatureNamed("patch config-features")
+	f.Setup("enable OIDC", PatchConfigMap("config-features",
+		[]byte(`{"data":{"authentication-oidc":"enabled"}}`),
+		WithConfigMapReadiness(func(ctx context.Context) (bool, error) {
+			readinessChecks++
+			return true, nil
+		}),
+	))
+	f.Setup("add a flag", PatchConfigMap("config-features",
+		[]byte(`{"metadata":{"labels":{"patched":"true"}},"data":{"new-trigger-filters":"enabled"},"binaryData":{"ca.crt":"cGF0Y2hlZA=="}}`)), feature.After("enable OIDC"))
+	f.Assert("patched", func(ctx context.Context, t feature.T) {
+		cm, err := kubeclient.Get(ctx).CoreV1().ConfigMaps("knative-eventing").Get(ctx, "config-features", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"authentication-oidc": "enabled",
+			"kreference-group":    "disabled",
+			"new-trigger-filters": "enabled",
+		}, cm.Data)
+		require.Equal(t, "true", cm.Labels["patched"])
+		require.Equal(t, "patched", string(cm.BinaryData["ca.crt"]))
+
+		original := state.MustGet[configMapContent](ctx, t, configMapStateKey("knative-eventing", "config-features"))
+		require.Equal(t, "disabled", original.Data["authentication-oidc"])
+
+		lease, err := kubeclient.Get(ctx).CoordinationV1().Leases("knative-eventing").Get(ctx, "rekt-configmap-config-features", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.NotEmpty(t, *lease.Spec.HolderIdentity)
+	})
+	env.Test(ctx, t, f)
+
+	cm, err := kubeclient.Get(ctx).CoreV1().ConfigMaps("knative-eventing").Get(ctx, "config-features", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"authentication-oidc": "disabled", "kreference-group": "disabled"}, cm.Data)
+	require.Equal(t, map[string]string{"app": "eventing"}, cm.Labels)
+	require.Equal(t, map[string][]byte{"ca.crt": []byte("original")}, cm.BinaryData)
+	// Once after patching, once after restoring.
+	require.Equal(t, 2, readinessChecks)
+
+	_, err = kubeclient.Get(ctx).CoordinationV1().Leases("knative-eventing").Get(ctx, "rekt-configmap-config-features", metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "lease not released: %v", err)
+}
+
+func TestAcquireLease(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	ctx := context.Background()
+	first, second := feature.NewFeatureNamed("first"), feature.NewFeatureNamed("second")
+
+	l, acquired, err := acquireLease(ctx, client, first, "knative-eventing", "rekt-configmap-config-features", time.Millisecond, time.Second, t.Logf)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	again, acquired, err := acquireLease(ctx, client, first, "knative-eventing", "rekt-configmap-config-features", time.Millisecond, time.Second, t.Logf)
+	require.NoError(t, err)
+	require.False(t, acquired)
+	require.Same(t, l, again)
+
+	// The features waiting for each other give up after the timeout.
+	_, _, err = acquireLease(ctx, client, second, "knative-eventing", "rekt-configmap-config-features", time.Millisecond, 20*time.Millisecond, t.Logf)
+	require.ErrorContains(t, err, "held by "+l.holder)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, l.release(ctx))
+	l, acquired, err = acquireLease(ctx, client, second, "knative-eventing", "rekt-configmap-config-features", time.Millisecond, time.Second, t.Logf)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	require.NoError(t, l.release(ctx))
+}
+
+func TestAcquireExpiredLease(t *testing.T) {
+	renewed := metav1.NewMicroTime(time.Now().Add(-2 * leaseDuration))
+	client := fake.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "knative-eventing", Name: "rekt-configmap-config-features"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.String("crashed"),
+			LeaseDurationSeconds: ptr.Int32(60),
+			RenewTime:            &renewed,
+		},
+	})
+	ctx := context.Background()
+
+	l, acquired, err := acquireLease(ctx, client, feature.NewFeatureNamed("f"), "knative-eventing", "rekt-configmap-config-features", time.Millisecond, time.Second, t.Logf)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	require.NoError(t, l.release(ctx))
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knative
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/ptr"
+
+	"knative.dev/reconciler-test/pkg/feature"
+)
+
+const (
+	// leaseDuration is how long a lease is held without being renewed, so
+	// that the lease of a process that died expires.
+	leaseDuration = 60 * time.Second
+	// leaseRenewPeriod is how often the lease is renewed while held.
+	leaseRenewPeriod = leaseDuration / 3
+)
+
+// configMapLease is a Lease held by a feature, serializing the changes to a
+// ConfigMap across the test processes sharing a cluster.
+type configMapLease struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	holder    string
+	// owner is the feature holding the lease, it can patch the ConfigMap
+	// again without waiting.
+	owner *feature.Feature
+	// mu serializes the patches of the steps of the owner.
+	mu sync.Mutex
+
+	stop    context.CancelFunc
+	stopped chan struct{}
+}
+
+var (
+	leasesMu sync.Mutex
+	// leases are the leases held by the process, by namespace/name.
+	leases = make(map[string]*configMapLease)
+)
+
+// acquireLease waits until the Lease namespace/name is free, and takes it for
+// the feature f. It returns whether the lease was taken, or already held by f.
+// It waits at most timeout, since two features patching the same ConfigMaps
+// in opposite orders wait for each other forever.
+func acquireLease(ctx context.Context, client kubernetes.Interface, f *feature.Feature, namespace, name string, interval, timeout time.Duration, logf func(string, ...interface{})) (*configMapLease, bool, error) {
+	key := namespace + "/" + name
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	hostname, _ := os.Hostname()
+	l := &configMapLease{
+		client:    client,
+		namespace: namespace,
+		name:      name,
+		holder:    hostname + "_" + uuid.NewString(),
+		owner:     f,
+	}
+
+	waiting := ""
+	for {
+		leasesMu.Lock()
+		held, ok := leases[key]
+		leasesMu.Unlock()
+		if ok && held.owner == f {
+			return held, false, nil
+		}
+
+		current, err := l.tryAcquire(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		if current == "" {
+			leasesMu.Lock()
+			leases[key] = l
+			leasesMu.Unlock()
+			l.renew()
+			return l, true, nil
+		}
+		if current != waiting {
+			logf("Waiting for Lease %s held by %s", key, current)
+			waiting = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, false, fmt.Errorf("failed to acquire Lease %s held by %s within %s, are ConfigMaps patched in different orders? %w", key, current, timeout, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// tryAcquire takes the lease when it doesn't exist or expired, otherwise it
+// returns its holder.
+func (l *configMapLease) tryAcquire(ctx context.Context) (string, error) {
+	leases := l.client.CoordinationV1().Leases(l.namespace)
+	now := metav1.NewMicroTime(time.Now())
+
+	current, err := leases.Get(ctx, l.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = leases.Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: l.name,
+				Labels: map[string]string{
+					"app.kubernetes.io/component": "reconciler-test",
+					"app.kubernetes.io/name":      "reconciler-test",
+				},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       ptr.String(l.holder),
+				LeaseDurationSeconds: ptr.Int32(int32(leaseDuration.Seconds())),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			return "another process", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to create Lease %s/%s: %w", l.namespace, l.name, err)
+		}
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get Lease %s/%s: %w", l.namespace, l.name, err)
+	}
+
+	if holder := ptr.StringValue(current.Spec.HolderIdentity); holder != "" && !leaseExpired(current) {
+		return holder, nil
+	}
+	current.Spec.HolderIdentity = ptr.String(l.holder)
+	current.Spec.LeaseDurationSeconds = ptr.Int32(int32(leaseDuration.Seconds()))
+	current.Spec.AcquireTime = &now
+	current.Spec.RenewTime = &now
+	_, err = leases.Update(ctx, current, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		return "another process", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to update Lease %s/%s: %w", l.namespace, l.name, err)
+	}
+	return "", nil
+}
+
+func leaseExpired(l *coordinationv1.Lease) bool {
+	if l.Spec.RenewTime == nil || l.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	return time.Since(l.Spec.RenewTime.Time) > time.Duration(*l.Spec.LeaseDurationSeconds)*time.Second
+}
+
+// renew renews the lease in the background until it is released.
+func (l *configMapLease) renew() {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.stop = cancel
+	l.stopped = make(chan struct{})
+	go func() {
+		defer close(l.stopped)
+		ticker := time.NewTicker(leaseRenewPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			leases := l.client.CoordinationV1().Leases(l.namespace)
+			current, err := leases.Get(ctx, l.name, metav1.GetOptions{})
+			if err != nil || ptr.StringValue(current.Spec.HolderIdentity) != l.holder {
+				continue
+			}
+			now := metav1.NewMicroTime(time.Now())
+			current.Spec.RenewTime = &now
+			_, _ = leases.Update(ctx, current, metav1.UpdateOptions{})
+		}
+	}()
+}
+
+// release stops renewing the lease and deletes it, when still held.
+func (l *configMapLease) release(ctx context.Context) error {
+	leasesMu.Lock()
+	delete(leases, l.namespace+"/"+l.name)
+	leasesMu.Unlock()
+
+	l.stop()
+	<-l.stopped
+
+	leases := l.client.CoordinationV1().Leases(l.namespace)
+	current, err := leases.Get(ctx, l.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get Lease %s/%s: %w", l.namespace, l.name, err)
+	}
+	if ptr.StringValue(current.Spec.HolderIdentity) != l.holder {
+		return nil
+	}
+	err = leases.Delete(ctx, l.name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &current.UID, ResourceVersion: &current.ResourceVersion},
+	})
+	if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+		return fmt.Errorf("failed to delete Lease %s/%s: %w", l.namespace, l.name, err)
+	}
+	return nil
+}
@@ -65,7 +65,7 @@ func InstallYamlFS(ctx context.Context, fsys fs.FS, base map[string]interface{})
 		return manifest.ApplyAll()
 	})
 	if err != nil {
-		return manifest, err
+		return manifest, environment.DescribeQuotaExceeded(ctx, err)
 	}
 
 	// Save the refs to Environment and Feature
@@ -96,7 +96,7 @@ func (f *YamlManifest) Apply(spec *unstructured.Unstructured) error {
 			if errors.IsAlreadyExists(err) {
 				return f.Apply(spec)
 			}
-			return fmt.Errorf("failed to create resource %v - Resource:\n%s", err, toYaml(spec))
+			return fmt.Errorf("failed to create resource %w - Resource:\n%s", err, toYaml(spec))
 		}
 	} else {
 		// Update existing one
@@ -108,7 +108,7 @@ func (f *YamlManifest) Apply(spec *unstructured.Unstructured) error {
 				return err
 			})
 			if err != nil {
-				return fmt.Errorf("failed to update resource %v - Resource:\n%s", err, toYaml(spec))
+				return fmt.Errorf("failed to update resource %w - Resource:\n%s", err, toYaml(spec))
 			}
 		}
 	}
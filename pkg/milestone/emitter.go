@@ -58,6 +58,32 @@ type Emitter interface {
 	Exception(reason, messageFormat string, messageA ...interface{})
 }
 
+// SkipEmitter is implemented by the Emitters that send an event when a
+// feature is skipped without being tested.
+type SkipEmitter interface {
+	TestSkipped(feature, reason string, t feature.T)
+}
+
+// QueueEmitter is implemented by the Emitters that send an event when a
+// feature waited for one of the --rekt.max-parallel-features slots.
+type QueueEmitter interface {
+	TestQueued(feature string, wait time.Duration, t feature.T)
+}
+
+// EmitTestSkipped calls TestSkipped on the emitter if it is a SkipEmitter.
+func EmitTestSkipped(emitter Emitter, feature, reason string, t feature.T) {
+	if e, ok := emitter.(SkipEmitter); ok {
+		e.TestSkipped(feature, reason, t)
+	}
+}
+
+// EmitTestQueued calls TestQueued on the emitter if it is a QueueEmitter.
+func EmitTestQueued(emitter Emitter, feature string, wait time.Duration, t feature.T) {
+	if e, ok := emitter.(QueueEmitter); ok {
+		e.TestQueued(feature, wait, t)
+	}
+}
+
 type Result interface {
 	Failed() bool
 }
@@ -105,6 +131,11 @@ type NilSafeClient struct {
 	Factory *Factory
 }
 
+var (
+	_ SkipEmitter  = (*NilSafeClient)(nil)
+	_ QueueEmitter = (*NilSafeClient)(nil)
+)
+
 func (n *NilSafeClient) Environment(env map[string]string) {
 	if n == nil || n.Client == nil {
 		return
@@ -133,6 +164,13 @@ func (n *NilSafeClient) TestStarted(feature string, t feature.T) {
 	n.Event(context.Background(), n.Factory.TestStarted(feature, t.Name()))
 }
 
+func (n *NilSafeClient) TestQueued(feature string, wait time.Duration, t feature.T) {
+	if n == nil || n.Client == nil {
+		return
+	}
+	n.Event(context.Background(), n.Factory.TestQueued(feature, t.Name(), wait))
+}
+
 func (n *NilSafeClient) TestFinished(feature string, t feature.T) {
 	if n == nil || n.Client == nil {
 		return
@@ -140,20 +178,34 @@ func (n *NilSafeClient) TestFinished(feature string, t feature.T) {
 	n.Event(context.Background(), n.Factory.TestFinished(feature, t.Name(), t.Skipped(), t.Failed()))
 }
 
+func (n *NilSafeClient) TestSkipped(feature, reason string, t feature.T) {
+	if n == nil || n.Client == nil {
+		return
+	}
+	n.Event(context.Background(), n.Factory.TestSkipped(feature, t.Name(), reason))
+}
+
 func (n *NilSafeClient) StepsPlanned(feature string, steps map[feature.Timing][]feature.Step, t feature.T) {
 	if n == nil || n.Client == nil {
 		return
 	}
 
 	sm := make(map[string][]string)
+	deps := make(map[string]map[string][]string)
 	for k, v := range steps {
 		sm[k.String()] = make([]string, len(v))
 		for i, step := range v {
 			sm[k.String()][i] = step.Name
+			if len(step.Deps) > 0 {
+				if deps[k.String()] == nil {
+					deps[k.String()] = make(map[string][]string)
+				}
+				deps[k.String()][step.Name] = step.Deps
+			}
 		}
 	}
 
-	n.Event(context.Background(), n.Factory.StepsPlanned(feature, sm, t.Name()))
+	n.Event(context.Background(), n.Factory.StepsPlannedWithDependencies(feature, sm, deps, t.Name()))
 }
 
 func (n *NilSafeClient) StepStarted(feature string, step *feature.Step, t feature.T) {
@@ -17,6 +17,8 @@ limitations under the License.
 package milestone
 
 import (
+	"time"
+
 	"knative.dev/reconciler-test/pkg/feature"
 )
 
@@ -29,6 +31,11 @@ type compositeEmitter struct {
 	emitters []Emitter
 }
 
+var (
+	_ SkipEmitter  = compositeEmitter{}
+	_ QueueEmitter = compositeEmitter{}
+)
+
 func (c compositeEmitter) Environment(env map[string]string) {
 	c.foreach(func(emitter Emitter) { emitter.Environment(env) })
 }
@@ -45,10 +52,18 @@ func (c compositeEmitter) TestStarted(feature string, t feature.T) {
 	c.foreach(func(emitter Emitter) { emitter.TestStarted(feature, t) })
 }
 
+func (c compositeEmitter) TestQueued(feature string, wait time.Duration, t feature.T) {
+	c.foreach(func(emitter Emitter) { EmitTestQueued(emitter, feature, wait, t) })
+}
+
 func (c compositeEmitter) TestFinished(feature string, t feature.T) {
 	c.foreach(func(emitter Emitter) { emitter.TestFinished(feature, t) })
 }
 
+func (c compositeEmitter) TestSkipped(feature, reason string, t feature.T) {
+	c.foreach(func(emitter Emitter) { EmitTestSkipped(emitter, feature, reason, t) })
+}
+
 func (c compositeEmitter) StepsPlanned(feature string, steps map[feature.Timing][]feature.Step, t feature.T) {
 	c.foreach(func(emitter Emitter) { emitter.StepsPlanned(feature, steps, t) })
 }
@@ -0,0 +1,52 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package milestone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/feature/featuretest"
+)
+
+// basicEmitter only implements Emitter, like the emitters written before the
+// optional events were added.
+type basicEmitter struct {
+	Emitter
+}
+
+type skipRecorder struct {
+	Emitter
+
+	skipped []string
+}
+
+func (r *skipRecorder) TestSkipped(feature, reason string, t feature.T) {
+	r.skipped = append(r.skipped, feature+": "+reason)
+}
+
+func TestComposeOptionalEvents(t *testing.T) {
+	recorder := &skipRecorder{Emitter: Compose()}
+	emitter := Compose(basicEmitter{Emitter: Compose()}, recorder)
+
+	EmitTestSkipped(emitter, "feature", "no match", featuretest.New("test"))
+	EmitTestQueued(emitter, "feature", 0, featuretest.New("test"))
+
+	require.Equal(t, []string{"feature: no match"}, recorder.skipped)
+}
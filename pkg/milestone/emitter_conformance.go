@@ -0,0 +1,373 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package milestone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"knative.dev/pkg/logging"
+
+	"knative.dev/reconciler-test/pkg/feature"
+)
+
+// Results of an assert step in a ConformanceReport.
+const (
+	StepPassed  = "passed"
+	StepFailed  = "failed"
+	StepSkipped = "skipped"
+)
+
+// levelsOrder is the order in which levels are reported, from the strongest
+// requirement to the weakest.
+var levelsOrder = []feature.Levels{feature.Must, feature.MustNot, feature.Should, feature.ShouldNot, feature.May}
+
+// ConformanceReport is the conformance statement of a run, based on the
+// results of the Assert steps of each feature.
+type ConformanceReport struct {
+	Name     string                `json:"name"`
+	Verdict  string                `json:"verdict"`
+	Summary  []LevelSummary        `json:"summary"`
+	Features []*FeatureConformance `json:"features"`
+}
+
+// LevelSummary counts the assert step results of a level.
+type LevelSummary struct {
+	Level   string `json:"level"`
+	Passed  int    `json:"passed"`
+	Failed  int    `json:"failed"`
+	Skipped int    `json:"skipped"`
+}
+
+// FeatureConformance holds the assert step results of a feature grouped by
+// level.
+type FeatureConformance struct {
+	Name       string              `json:"name"`
+	SkipReason string              `json:"skipReason,omitempty"`
+	Levels     []*LevelConformance `json:"levels,omitempty"`
+}
+
+// LevelConformance holds the assert step results of a level.
+type LevelConformance struct {
+	Level string             `json:"level"`
+	Steps []*StepConformance `json:"steps"`
+}
+
+// StepConformance is the result of an assert step.
+type StepConformance struct {
+	Name   string `json:"name"`
+	State  string `json:"state"`
+	Result string `json:"result"`
+}
+
+// ConformanceReporter is an Emitter that builds a ConformanceReport from the
+// assert steps of the features it observes. The report is written to
+// $ARTIFACTS as JSON and Markdown every time an environment finishes, so a
+// single reporter can be shared by all the environments of a test run.
+type ConformanceReporter struct {
+	ctx  context.Context
+	name string
+
+	mu       sync.Mutex
+	features []*FeatureConformance
+
+	// writeMu serializes the writes of the report, so that the report of the
+	// last environment finishing is the one left.
+	writeMu sync.Mutex
+}
+
+var _ Emitter = (*ConformanceReporter)(nil)
+
+// NewConformanceReporter creates a ConformanceReporter, name is used as the
+// report title and as the base name of the report files. Errors are logged to
+// the logger of ctx.
+func NewConformanceReporter(ctx context.Context, name string) *ConformanceReporter {
+	return &ConformanceReporter{ctx: ctx, name: name}
+}
+
+// Report returns a snapshot of the conformance report of the steps observed so
+// far.
+func (r *ConformanceReporter) Report() *ConformanceReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &ConformanceReport{
+		Name:     r.name,
+		Summary:  make([]LevelSummary, len(levelsOrder)),
+		Features: make([]*FeatureConformance, 0, len(r.features)),
+	}
+	counts := make(map[string]*LevelSummary, len(levelsOrder))
+	for i, l := range levelsOrder {
+		report.Summary[i].Level = l.String()
+		counts[l.String()] = &report.Summary[i]
+	}
+	for _, f := range r.features {
+		fc := &FeatureConformance{Name: f.Name, SkipReason: f.SkipReason}
+		report.Features = append(report.Features, fc)
+		for _, l := range f.Levels {
+			lc := &LevelConformance{Level: l.Level}
+			fc.Levels = append(fc.Levels, lc)
+			for _, s := range l.Steps {
+				s := *s
+				lc.Steps = append(lc.Steps, &s)
+			}
+
+			summary, ok := counts[l.Level]
+			if !ok {
+				continue
+			}
+			for _, s := range l.Steps {
+				switch s.Result {
+				case StepPassed:
+					summary.Passed++
+				case StepFailed:
+					summary.Failed++
+				default:
+					summary.Skipped++
+				}
+			}
+		}
+	}
+	report.Verdict = verdict(counts)
+	return report
+}
+
+func verdict(counts map[string]*LevelSummary) string {
+	failed := func(levels ...feature.Levels) int {
+		n := 0
+		for _, l := range levels {
+			n += counts[l.String()].Failed
+		}
+		return n
+	}
+	plural := func(n int, what string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s violation", what)
+		}
+		return fmt.Sprintf("%d %s violations", n, what)
+	}
+
+	if n := failed(feature.Must, feature.MustNot); n > 0 {
+		return "not conformant, " + plural(n, "MUST")
+	}
+	// Conformance can't be claimed without any MUST step passing.
+	if counts[feature.Must.String()].Passed+counts[feature.MustNot.String()].Passed == 0 {
+		for _, c := range counts {
+			if c.Passed+c.Failed > 0 {
+				return "no MUST assertions passed"
+			}
+		}
+		return "no assertions run"
+	}
+	if n := failed(feature.Should, feature.ShouldNot); n > 0 {
+		return "conformant at MUST level, " + plural(n, "SHOULD")
+	}
+	if n := failed(feature.May); n > 0 {
+		return "conformant at SHOULD level, " + plural(n, "MAY")
+	}
+	return "conformant at MAY level"
+}
+
+func (r *ConformanceReporter) feature(name string) *FeatureConformance {
+	for _, f := range r.features {
+		if f.Name == name {
+			return f
+		}
+	}
+	f := &FeatureConformance{Name: name}
+	r.features = append(r.features, f)
+	return f
+}
+
+func (f *FeatureConformance) step(step *feature.Step) *StepConformance {
+	var level *LevelConformance
+	for _, l := range f.Levels {
+		if l.Level == step.L.String() {
+			level = l
+		}
+	}
+	if level == nil {
+		level = &LevelConformance{Level: step.L.String()}
+		f.Levels = append(f.Levels, level)
+	}
+	for _, s := range level.Steps {
+		if s.Name == step.Name {
+			return s
+		}
+	}
+	s := &StepConformance{Name: step.Name, State: step.S.String(), Result: StepSkipped}
+	level.Steps = append(level.Steps, s)
+	return s
+}
+
+func (r *ConformanceReporter) Environment(env map[string]string) {
+}
+
+func (r *ConformanceReporter) NamespaceCreated(namespace string) {
+}
+
+func (r *ConformanceReporter) NamespaceDeleted(namespace string) {
+}
+
+func (r *ConformanceReporter) TestStarted(feature string, t feature.T) {
+}
+
+func (r *ConformanceReporter) TestQueued(feature string, wait time.Duration, t feature.T) {
+}
+
+func (r *ConformanceReporter) TestFinished(feature string, t feature.T) {
+}
+
+func (r *ConformanceReporter) TestSkipped(feature, reason string, t feature.T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.feature(feature).SkipReason = reason
+}
+
+// StepsPlanned registers every assert step as skipped, until it is reported as
+// finished.
+func (r *ConformanceReporter) StepsPlanned(featureName string, steps map[feature.Timing][]feature.Step, t feature.T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := r.feature(featureName)
+	for i := range steps[feature.Assert] {
+		f.step(&steps[feature.Assert][i])
+	}
+}
+
+func (r *ConformanceReporter) StepStarted(feature string, step *feature.Step, t feature.T) {
+}
+
+func (r *ConformanceReporter) StepFinished(featureName string, step *feature.Step, t feature.T) {
+	if step.T != feature.Assert {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.feature(featureName).step(step)
+	switch {
+	case t.Failed():
+		s.Result = StepFailed
+	case t.Skipped():
+		s.Result = StepSkipped
+	default:
+		s.Result = StepPassed
+	}
+}
+
+func (r *ConformanceReporter) TestSetStarted(featureSet string, t feature.T) {
+}
+
+func (r *ConformanceReporter) TestSetFinished(featureSet string, t feature.T) {
+}
+
+// Finished writes the report to $ARTIFACTS, or to the temporary directory
+// when ARTIFACTS is not set.
+func (r *ConformanceReporter) Finished(result Result) {
+	dir := os.Getenv("ARTIFACTS")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	if err := r.Write(dir); err != nil {
+		logging.FromContext(r.ctx).Error("Failed to write the conformance report: ", err)
+	}
+}
+
+func (r *ConformanceReporter) Exception(reason, messageFormat string, messageA ...interface{}) {
+}
+
+// Write writes the report to dir as <name>.json and <name>.md. The files are
+// replaced at once, since every environment sharing the reporter writes them
+// when it finishes.
+func (r *ConformanceReporter) Write(dir string) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	report := r.Report()
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	base := filepath.Join(dir, feature.MakeK8sNamePrefix(r.name))
+	if err := writeFileAtomically(base+".json", b); err != nil {
+		return err
+	}
+	return writeFileAtomically(base+".md", []byte(report.Markdown()))
+}
+
+// writeFileAtomically writes the file through a temporary file renamed over
+// it, so that readers never see a partial file.
+func writeFileAtomically(path string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
+
+// Markdown renders the report as Markdown.
+func (cr *ConformanceReport) Markdown() string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "# Conformance report: %s\n\n", cr.Name)
+	fmt.Fprintf(sb, "**Verdict:** %s\n\n", cr.Verdict)
+
+	sb.WriteString("| Level | Passed | Failed | Skipped |\n")
+	sb.WriteString("| ----- | ------ | ------ | ------- |\n")
+	for _, s := range cr.Summary {
+		fmt.Fprintf(sb, "| %s | %d | %d | %d |\n", s.Level, s.Passed, s.Failed, s.Skipped)
+	}
+
+	for _, f := range cr.Features {
+		fmt.Fprintf(sb, "\n## %s\n", f.Name)
+		if f.SkipReason != "" {
+			fmt.Fprintf(sb, "\nSkipped: %s\n", f.SkipReason)
+		}
+		for _, l := range f.Levels {
+			fmt.Fprintf(sb, "\n### %s\n\n", l.Level)
+			sb.WriteString("| Step | State | Result |\n")
+			sb.WriteString("| ---- | ----- | ------ |\n")
+			for _, s := range l.Steps {
+				fmt.Fprintf(sb, "| %s | %s | %s |\n", strings.ReplaceAll(s.Name, "|", `\|`), s.State, s.Result)
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package milestone
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/feature/featuretest"
+)
+
+type result int
+
+const (
+	passed result = iota
+	failed
+	skipped
+)
+
+// run returns the T of a step with the given result.
+func (r result) run() *featuretest.T {
+	return featuretest.New("step").Run(context.Background(), func(ctx context.Context, t feature.T) {
+		switch r {
+		case failed:
+			t.Fail()
+		case skipped:
+			t.SkipNow()
+		}
+	})
+}
+
+func TestConformanceReporter(t *testing.T) {
+	tests := []struct {
+		name    string
+		results map[feature.Levels][]result
+		verdict string
+	}{{
+		name: "all passed",
+		results: map[feature.Levels][]result{
+			feature.Must:   {passed},
+			feature.Should: {passed},
+			feature.May:    {passed},
+		},
+		verdict: "conformant at MAY level",
+	}, {
+		name: "should violations",
+		results: map[feature.Levels][]result{
+			feature.Must:      {passed, passed},
+			feature.Should:    {failed, failed, passed},
+			feature.ShouldNot: {failed},
+		},
+		verdict: "conformant at MUST level, 3 SHOULD violations",
+	}, {
+		name: "must violation",
+		results: map[feature.Levels][]result{
+			feature.Must:    {passed},
+			feature.MustNot: {failed},
+			feature.Should:  {failed},
+		},
+		verdict: "not conformant, 1 MUST violation",
+	}, {
+		name: "may failures",
+		results: map[feature.Levels][]result{
+			feature.Must: {passed},
+			feature.May:  {failed, skipped},
+		},
+		verdict: "conformant at SHOULD level, 1 MAY violation",
+	}, {
+		name: "no must step",
+		results: map[feature.Levels][]result{
+			feature.Should: {passed},
+			feature.May:    {passed},
+		},
+		verdict: "no MUST assertions passed",
+	}, {
+		name:    "no step",
+		verdict: "no assertions run",
+	}, {
+		name: "skipped steps",
+		results: map[feature.Levels][]result{
+			feature.Must: {skipped},
+		},
+		verdict: "no assertions run",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewConformanceReporter(context.Background(), tt.name)
+
+			var steps []feature.Step
+			for _, level := range levelsOrder {
+				for i := range tt.results[level] {
+					steps = append(steps, feature.Step{
+						Name: level.String() + "-" + string(rune('a'+i)),
+						S:    feature.Stable,
+						L:    level,
+						T:    feature.Assert,
+					})
+				}
+			}
+			r.StepsPlanned("f", map[feature.Timing][]feature.Step{feature.Assert: steps}, nil)
+
+			for _, s := range steps {
+				s := s
+				ft := tt.results[s.L][s.Name[len(s.Name)-1]-'a'].run()
+				r.StepStarted("f", &s, ft)
+				r.StepFinished("f", &s, ft)
+			}
+
+			require.Equal(t, tt.verdict, r.Report().Verdict)
+		})
+	}
+}
+
+func TestConformanceReporterWrite(t *testing.T) {
+	r := NewConformanceReporter(context.Background(), "conformance")
+
+	r.StepsPlanned("f1", map[feature.Timing][]feature.Step{
+		feature.Setup: {{Name: "setup", T: feature.Setup}},
+		feature.Assert: {
+			{Name: "passes", S: feature.Stable, L: feature.Must, T: feature.Assert},
+			{Name: "fails", S: feature.Beta, L: feature.Should, T: feature.Assert},
+			{Name: "not run", S: feature.Alpha, L: feature.May, T: feature.Assert},
+		},
+	}, nil)
+	for _, s := range []struct {
+		step   feature.Step
+		result result
+	}{
+		{step: feature.Step{Name: "setup", T: feature.Setup}, result: failed},
+		{step: feature.Step{Name: "passes", S: feature.Stable, L: feature.Must, T: feature.Assert}, result: passed},
+		{step: feature.Step{Name: "fails", S: feature.Beta, L: feature.Should, T: feature.Assert}, result: failed},
+	} {
+		s := s
+		r.StepFinished("f1", &s.step, s.result.run())
+	}
+	r.TestSkipped("f2", "--feature=f1 doesn't match", nil)
+
+	dir := t.TempDir()
+	require.NoError(t, r.Write(dir))
+
+	md, err := os.ReadFile(filepath.Join(dir, "conformance.md"))
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		"# Conformance report: conformance",
+		"",
+		"**Verdict:** conformant at MUST level, 1 SHOULD violation",
+		"",
+		"| Level | Passed | Failed | Skipped |",
+		"| ----- | ------ | ------ | ------- |",
+		"| MUST | 1 | 0 | 0 |",
+		"| MUST NOT | 0 | 0 | 0 |",
+		"| SHOULD | 0 | 1 | 0 |",
+		"| SHOULD NOT | 0 | 0 | 0 |",
+		"| MAY | 0 | 0 | 1 |",
+		"",
+		"## f1",
+		"",
+		"### MUST",
+		"",
+		"| Step | State | Result |",
+		"| ---- | ----- | ------ |",
+		"| passes | Stable | passed |",
+		"",
+		"### SHOULD",
+		"",
+		"| Step | State | Result |",
+		"| ---- | ----- | ------ |",
+		"| fails | Beta | failed |",
+		"",
+		"### MAY",
+		"",
+		"| Step | State | Result |",
+		"| ---- | ----- | ------ |",
+		"| not run | Alpha | skipped |",
+		"",
+		"## f2",
+		"",
+		"Skipped: --feature=f1 doesn't match",
+		"",
+	}, "\n"), string(md))
+
+	b, err := os.ReadFile(filepath.Join(dir, "conformance.json"))
+	require.NoError(t, err)
+	report := &ConformanceReport{}
+	require.NoError(t, json.Unmarshal(b, report))
+	require.Equal(t, r.Report(), report)
+}
+
+func TestConformanceReporterFinishedWithoutArtifacts(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("ARTIFACTS", "")
+	t.Setenv("TMPDIR", tmp)
+	r := NewConformanceReporter(context.Background(), "conformance")
+
+	// Every environment sharing the reporter writes it when it finishes.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Finished(passed.run())
+		}()
+	}
+	wg.Wait()
+
+	entries, err := os.ReadDir(tmp)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	require.ElementsMatch(t, []string{"conformance.json", "conformance.md"}, names)
+
+	b, err := os.ReadFile(filepath.Join(tmp, "conformance.json"))
+	require.NoError(t, err)
+	var report ConformanceReport
+	require.NoError(t, json.Unmarshal(b, &report))
+	require.Equal(t, "no assertions run", report.Verdict)
+}
@@ -22,11 +22,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"sort"
 	"time"
 
 	"go.uber.org/zap"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/logging"
@@ -34,6 +32,7 @@ import (
 	testlog "knative.dev/reconciler-test/pkg/logging"
 
 	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/internal/k8sevents"
 )
 
 // LogEmitter is an Emitter that logs milestone events.
@@ -70,10 +69,18 @@ func (l LogEmitter) TestStarted(feature string, t feature.T) {
 	l.log().Debug(feature, " Test started")
 }
 
+func (l LogEmitter) TestQueued(feature string, wait time.Duration, t feature.T) {
+	l.log().Debug(feature, " Test queued for ", wait)
+}
+
 func (l LogEmitter) TestFinished(feature string, t feature.T) {
 	l.log().Debug(feature, " Test Finished")
 }
 
+func (l LogEmitter) TestSkipped(feature, reason string, t feature.T) {
+	l.log().Debug(feature, " Test skipped: ", reason)
+}
+
 func (l LogEmitter) StepsPlanned(feature string, steps map[feature.Timing][]feature.Step, t feature.T) {
 	bytes, err := json.MarshalIndent(steps, " ", " ")
 	if err != nil {
@@ -138,7 +145,7 @@ func (l LogEmitter) dumpEvents() {
 	defer func() {
 		_ = dump.Close()
 	}()
-	content, err := json.MarshalIndent(sortEventsByTime(events.Items), "", "  ")
+	content, err := json.MarshalIndent(k8sevents.SortByTime(events.Items), "", "  ")
 	if err != nil {
 		l.log().Fatal(err)
 	}
@@ -159,22 +166,6 @@ func (l LogEmitter) newDumpFile() *os.File {
 	return f
 }
 
-func sortEventsByTime(items []corev1.Event) []corev1.Event {
-	sort.SliceStable(items, func(i, j int) bool {
-		return eventTime(items[i]).Before(eventTime(items[j]))
-	})
-	return items
-}
-
-func eventTime(e corev1.Event) time.Time {
-	// Some events might not contain last timestamp, in that case
-	// we fall back to the event time.
-	if e.LastTimestamp.Time.IsZero() {
-		return e.EventTime.Time
-	}
-	return e.LastTimestamp.Time
-}
-
 func (l LogEmitter) log() *zap.SugaredLogger {
 	return logging.FromContext(l.ctx).With("namespace", l.namespace)
 }
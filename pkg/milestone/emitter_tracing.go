@@ -18,6 +18,7 @@ package milestone
 
 import (
 	"context"
+	"time"
 
 	"knative.dev/reconciler-test/pkg/feature"
 )
@@ -43,9 +44,15 @@ func (e tracingEmitter) NamespaceDeleted(namespace string) {
 func (e tracingEmitter) TestStarted(feature string, t feature.T) {
 }
 
+func (e tracingEmitter) TestQueued(feature string, wait time.Duration, t feature.T) {
+}
+
 func (e tracingEmitter) TestFinished(feature string, t feature.T) {
 }
 
+func (e tracingEmitter) TestSkipped(feature, reason string, t feature.T) {
+}
+
 func (e tracingEmitter) StepsPlanned(feature string, steps map[feature.Timing][]feature.Step, t feature.T) {
 }
 
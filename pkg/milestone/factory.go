@@ -29,7 +29,9 @@ const (
 	NamespaceCreatedType = "dev.knative.rekt.namespace.created.v1"
 	NamespaceDeletedType = "dev.knative.rekt.namespace.deleted.v1"
 	TestStartedType      = "dev.knative.rekt.test.started.v1"
+	TestQueuedType       = "dev.knative.rekt.test.queued.v1"
 	TestFinishedType     = "dev.knative.rekt.test.finished.v1"
+	TestSkippedType      = "dev.knative.rekt.test.skipped.v1"
 	StepsPlannedType     = "dev.knative.rekt.steps.planned.v1"
 	StepStartedType      = "dev.knative.rekt.step.started.v1"
 	StepFinishedType     = "dev.knative.rekt.step.finished.v1"
@@ -113,6 +115,29 @@ func (ef *Factory) TestStarted(feature, testName string) cloudevents.Event {
 	return event
 }
 
+// TestQueued is sent when a feature starts after waiting for one of the
+// --rekt.max-parallel-features slots.
+func (ef *Factory) TestQueued(feature, testName string, wait time.Duration) cloudevents.Event {
+	event := ef.baseEvent(TestQueuedType)
+
+	lparts := strings.Split(testName, "/")
+	if len(lparts) > 0 {
+		event.SetExtension("testparent", lparts[0])
+	}
+
+	event.SetExtension("feature", feature)
+	event.SetExtension("testname", testName)
+
+	_ = event.SetData(cloudevents.ApplicationJSON, map[string]interface{}{
+		"feature":     feature,
+		"testName":    testName,
+		"wait":        wait.String(),
+		"waitSeconds": wait.Seconds(),
+	})
+
+	return event
+}
+
 func (ef *Factory) TestFinished(feature, testName string, skipped, failed bool) cloudevents.Event {
 	event := ef.baseEvent(TestFinishedType)
 
@@ -135,7 +160,33 @@ func (ef *Factory) TestFinished(feature, testName string, skipped, failed bool)
 	return event
 }
 
+func (ef *Factory) TestSkipped(feature, testName, reason string) cloudevents.Event {
+	event := ef.baseEvent(TestSkippedType)
+
+	lparts := strings.Split(testName, "/")
+	if len(lparts) > 0 {
+		event.SetExtension("testparent", lparts[0])
+	}
+
+	event.SetExtension("feature", feature)
+	event.SetExtension("testname", testName)
+
+	_ = event.SetData(cloudevents.ApplicationJSON, map[string]interface{}{
+		"feature":  feature,
+		"testName": testName,
+		"reason":   reason,
+	})
+
+	return event
+}
+
 func (ef *Factory) StepsPlanned(feature string, steps map[string][]string, testName string) cloudevents.Event {
+	return ef.StepsPlannedWithDependencies(feature, steps, nil, testName)
+}
+
+// StepsPlannedWithDependencies is like StepsPlanned, dependencies holds, for
+// each timing, the names of the steps that a step has to wait for.
+func (ef *Factory) StepsPlannedWithDependencies(feature string, steps map[string][]string, dependencies map[string]map[string][]string, testName string) cloudevents.Event {
 	event := ef.baseEvent(StepsPlannedType)
 
 	lparts := strings.Split(testName, "/")
@@ -149,11 +200,15 @@ func (ef *Factory) StepsPlanned(feature string, steps map[string][]string, testN
 	// TODO: we can log a whole lot of stuff here but we need a more formal structure to track
 	// where we are in the test to be able to assemble it.
 
-	_ = event.SetData(cloudevents.ApplicationJSON, map[string]interface{}{
+	data := map[string]interface{}{
 		"feature":  feature,
 		"steps":    steps,
 		"testName": testName,
-	})
+	}
+	if len(dependencies) > 0 {
+		data["dependencies"] = dependencies
+	}
+	_ = event.SetData(cloudevents.ApplicationJSON, data)
 
 	return event
 }
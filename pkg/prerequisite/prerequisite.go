@@ -0,0 +1,319 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package prerequisite provides checks of the cluster to use with
+// Feature.Prerequisite, for example:
+//
+//	f.Prerequisite("OIDC and cert-manager", prerequisite.AllOf(
+//		prerequisite.KnativeFeatureFlagEnabled("config-features", "authentication-oidc"),
+//		prerequisite.CRDInstalled(certificates),
+//	))
+//
+// The checks set the Reason of the result whether they are satisfied or not,
+// so that Not and the composition helpers report what was found. They only
+// return an error when the cluster can't be queried.
+package prerequisite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/knative"
+)
+
+var customResourceDefinitions = apiextensionsv1.SchemeGroupVersion.WithResource("customresourcedefinitions")
+
+// Annotations marking the default StorageClass.
+const (
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+func satisfied(format string, args ...interface{}) (feature.PrerequisiteResult, error) {
+	return feature.PrerequisiteResult{ShouldRun: true, Reason: fmt.Sprintf(format, args...)}, nil
+}
+
+func unsatisfied(format string, args ...interface{}) (feature.PrerequisiteResult, error) {
+	return feature.PrerequisiteResult{ShouldRun: false, Reason: fmt.Sprintf(format, args...)}, nil
+}
+
+// CRDInstalled checks that the CustomResourceDefinition of the resource is
+// established and serves the version of gvr.
+func CRDInstalled(gvr schema.GroupVersionResource) feature.ShouldRun {
+	return func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		name := gvr.GroupResource().String()
+		u, err := dynamicclient.Get(ctx).Resource(customResourceDefinitions).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return unsatisfied("CRD %s is not installed", name)
+		}
+		if err != nil {
+			return feature.PrerequisiteResult{}, fmt.Errorf("failed to get CRD %s: %w", name, err)
+		}
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, crd); err != nil {
+			return feature.PrerequisiteResult{}, fmt.Errorf("failed to convert CRD %s: %w", name, err)
+		}
+
+		established := false
+		for _, c := range crd.Status.Conditions {
+			if c.Type == apiextensionsv1.Established && c.Status == apiextensionsv1.ConditionTrue {
+				established = true
+			}
+		}
+		if !established {
+			return unsatisfied("CRD %s is not established", name)
+		}
+		var served []string
+		for _, v := range crd.Spec.Versions {
+			if v.Served {
+				served = append(served, v.Name)
+				if v.Name == gvr.Version {
+					return satisfied("CRD %s is installed and serves %s", name, gvr.Version)
+				}
+			}
+		}
+		return unsatisfied("CRD %s doesn't serve %s, served versions: [%s]", name, gvr.Version, strings.Join(served, ", "))
+	}
+}
+
+// APIResourceAvailable checks that the API server serves the resource, a
+// built-in, aggregated or custom resource.
+func APIResourceAvailable(gvr schema.GroupVersionResource) feature.ShouldRun {
+	return func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		gv := gvr.GroupVersion().String()
+		list, err := kubeclient.Get(ctx).Discovery().ServerResourcesForGroupVersion(gv)
+		if apierrors.IsNotFound(err) {
+			return unsatisfied("API group version %s is not served", gv)
+		}
+		if err != nil {
+			return feature.PrerequisiteResult{}, fmt.Errorf("failed to discover the resources of %s: %w", gv, err)
+		}
+		for _, r := range list.APIResources {
+			if r.Name == gvr.Resource {
+				return satisfied("API resource %s is available", gvr)
+			}
+		}
+		return unsatisfied("API resource %s is not served by %s", gvr.Resource, gv)
+	}
+}
+
+// ServerVersionAtLeast checks that the Kubernetes version of the API server is
+// at least minimum, for example "1.28".
+func ServerVersionAtLeast(minimum string) feature.ShouldRun {
+	return func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		want, err := version.ParseGeneric(minimum)
+		if err != nil {
+			return feature.PrerequisiteResult{}, fmt.Errorf("invalid minimum server version %q: %w", minimum, err)
+		}
+		info, err := kubeclient.Get(ctx).Discovery().ServerVersion()
+		if err != nil {
+			return feature.PrerequisiteResult{}, fmt.Errorf("failed to get the server version: %w", err)
+		}
+		got, err := version.ParseGeneric(info.GitVersion)
+		if err != nil {
+			return feature.PrerequisiteResult{}, fmt.Errorf("invalid server version %q: %w", info.GitVersion, err)
+		}
+		if got.LessThan(want) {
+			return unsatisfied("server version %s is older than %s", info.GitVersion, minimum)
+		}
+		return satisfied("server version %s is at least %s", info.GitVersion, minimum)
+	}
+}
+
+// NamespaceExists checks that the namespace exists and is not being deleted.
+func NamespaceExists(name string) feature.ShouldRun {
+	return func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		ns, err := kubeclient.Get(ctx).CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return unsatisfied("namespace %s doesn't exist", name)
+		}
+		if err != nil {
+			return feature.PrerequisiteResult{}, fmt.Errorf("failed to get namespace %s: %w", name, err)
+		}
+		if ns.DeletionTimestamp != nil {
+			return unsatisfied("namespace %s is being deleted", name)
+		}
+		return satisfied("namespace %s exists", name)
+	}
+}
+
+// DeploymentReady checks that the deployment rolled out and all its replicas
+// are ready.
+func DeploymentReady(namespace, name string) feature.ShouldRun {
+	return func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		d, err := kubeclient.Get(ctx).AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return unsatisfied("deployment %s/%s doesn't exist", namespace, name)
+		}
+		if err != nil {
+			return feature.PrerequisiteResult{}, fmt.Errorf("failed to get deployment %s/%s: %w", namespace, name, err)
+		}
+		if d.Status.ObservedGeneration < d.Generation {
+			return unsatisfied("deployment %s/%s generation %d is not observed yet", namespace, name, d.Generation)
+		}
+		for _, c := range d.Status.Conditions {
+			if c.Type == appsv1.DeploymentAvailable && c.Status != corev1.ConditionTrue {
+				return unsatisfied("deployment %s/%s is not available: %s", namespace, name, c.Message)
+			}
+		}
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		if d.Status.UpdatedReplicas < replicas || d.Status.ReadyReplicas < replicas {
+			return unsatisfied("deployment %s/%s has %d/%d updated and %d/%d ready replicas", namespace, name,
+				d.Status.UpdatedReplicas, replicas, d.Status.ReadyReplicas, replicas)
+		}
+		return satisfied("deployment %s/%s has %d/%d ready replicas", namespace, name, d.Status.ReadyReplicas, replicas)
+	}
+}
+
+// KnativeFeatureFlagEnabled checks that the key of the Knative feature flags
+// ConfigMap is "enabled" or "true". configMap is either "namespace/name" or
+// the name of a ConfigMap in the namespace set with knative.WithKnativeNamespace,
+// for example "config-features".
+func KnativeFeatureFlagEnabled(configMap, key string) feature.ShouldRun {
+	return func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		namespace, name, ok := strings.Cut(configMap, "/")
+		if !ok {
+			namespace, name = knative.KnativeNamespaceFromContext(ctx), configMap
+		}
+		cm, err := kubeclient.Get(ctx).CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return unsatisfied("ConfigMap %s/%s doesn't exist", namespace, name)
+		}
+		if err != nil {
+			return feature.PrerequisiteResult{}, fmt.Errorf("failed to get ConfigMap %s/%s: %w", namespace, name, err)
+		}
+		value, ok := cm.Data[key]
+		if !ok {
+			return unsatisfied("feature flag %s is not set in ConfigMap %s/%s", key, namespace, name)
+		}
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "enabled", "true":
+			return satisfied("feature flag %s is %s in ConfigMap %s/%s", key, value, namespace, name)
+		}
+		return unsatisfied("feature flag %s is %s in ConfigMap %s/%s", key, value, namespace, name)
+	}
+}
+
+// StorageClassDefault checks that the cluster has a default StorageClass, for
+// the PersistentVolumeClaims without storage class.
+func StorageClassDefault() feature.ShouldRun {
+	return func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		classes, err := kubeclient.Get(ctx).StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return feature.PrerequisiteResult{}, fmt.Errorf("failed to list the storage classes: %w", err)
+		}
+		names := make([]string, 0, len(classes.Items))
+		for _, sc := range classes.Items {
+			if sc.Annotations[defaultStorageClassAnnotation] == "true" || sc.Annotations[betaDefaultStorageClassAnnotation] == "true" {
+				return satisfied("StorageClass %s is the default", sc.Name)
+			}
+			names = append(names, sc.Name)
+		}
+		return unsatisfied("no default StorageClass among [%s]", strings.Join(names, ", "))
+	}
+}
+
+// NodeCountAtLeast checks that the cluster has at least n ready and
+// schedulable nodes.
+func NodeCountAtLeast(n int) feature.ShouldRun {
+	return func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		nodes, err := kubeclient.Get(ctx).CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return feature.PrerequisiteResult{}, fmt.Errorf("failed to list the nodes: %w", err)
+		}
+		ready := 0
+		for _, node := range nodes.Items {
+			if node.Spec.Unschedulable {
+				continue
+			}
+			for _, c := range node.Status.Conditions {
+				if c.Type == corev1.NodeReady && c.Status == corev1.ConditionTrue {
+					ready++
+				}
+			}
+		}
+		if ready < n {
+			return unsatisfied("cluster has %d ready schedulable nodes of %d, want at least %d", ready, len(nodes.Items), n)
+		}
+		return satisfied("cluster has %d ready schedulable nodes, at least %d", ready, n)
+	}
+}
+
+// AllOf checks that all the checks are satisfied, it stops at the first one
+// that isn't and reports its reason.
+func AllOf(checks ...feature.ShouldRun) feature.ShouldRun {
+	return func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		reasons := make([]string, 0, len(checks))
+		for _, check := range checks {
+			result, err := check(ctx, t)
+			if err != nil || !result.ShouldRun {
+				return result, err
+			}
+			reasons = append(reasons, result.Reason)
+		}
+		return feature.PrerequisiteResult{ShouldRun: true, Reason: strings.Join(reasons, " and ")}, nil
+	}
+}
+
+// AnyOf checks that one of the checks is satisfied, it stops at the first one
+// that is and reports its reason, or the reasons of all of them. The errors of
+// the checks are only returned when none is satisfied.
+func AnyOf(checks ...feature.ShouldRun) feature.ShouldRun {
+	return func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		reasons := make([]string, 0, len(checks))
+		var errs []error
+		for _, check := range checks {
+			result, err := check(ctx, t)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if result.ShouldRun {
+				return result, nil
+			}
+			reasons = append(reasons, result.Reason)
+		}
+		return feature.PrerequisiteResult{ShouldRun: false, Reason: strings.Join(reasons, " and ")}, errors.Join(errs...)
+	}
+}
+
+// Not checks that the check is not satisfied, keeping its reason.
+func Not(check feature.ShouldRun) feature.ShouldRun {
+	return func(ctx context.Context, t feature.T) (feature.PrerequisiteResult, error) {
+		result, err := check(ctx, t)
+		if err != nil {
+			return result, err
+		}
+		return feature.PrerequisiteResult{ShouldRun: !result.ShouldRun, Reason: result.Reason}, nil
+	}
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prerequisite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+	"knative.dev/pkg/ptr"
+
+	"knative.dev/reconciler-test/pkg/feature"
+	"knative.dev/reconciler-test/pkg/knative"
+)
+
+var (
+	brokers   = schema.GroupVersionResource{Group: "eventing.knative.dev", Version: "v1", Resource: "brokers"}
+	triggers  = schema.GroupVersionResource{Group: "eventing.knative.dev", Version: "v1", Resource: "triggers"}
+	channels  = schema.GroupVersionResource{Group: "messaging.knative.dev", Version: "v1", Resource: "channels"}
+	oldBroker = schema.GroupVersionResource{Group: "eventing.knative.dev", Version: "v1beta1", Resource: "brokers"}
+)
+
+func crd(name string, established bool, versions ...string) runtime.Object {
+	status := "False"
+	if established {
+		status = "True"
+	}
+	var vs []interface{}
+	for _, v := range versions {
+		vs = append(vs, map[string]interface{}{"name": v, "served": true, "storage": true})
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       map[string]interface{}{"versions": vs},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Established", "status": status}},
+		},
+	}}
+}
+
+func node(name string, ready, unschedulable bool) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}},
+	}
+}
+
+func deployment(name string, replicas, ready int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "knative-eventing", Name: name, Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.Int32(replicas)},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			UpdatedReplicas:    replicas,
+			ReadyReplicas:      ready,
+		},
+	}
+}
+
+func testContext(kubeObjects ...runtime.Object) context.Context {
+	ctx := context.Background()
+	ctx, _ = fakekubeclient.With(ctx, kubeObjects...)
+	ctx, _ = knative.WithKnativeNamespace("knative-eventing")(ctx, nil)
+	dc := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), nil,
+		crd("brokers.eventing.knative.dev", true, "v1"),
+		crd("channels.messaging.knative.dev", false, "v1"),
+	)
+	ctx = context.WithValue(ctx, dynamicclient.Key{}, dc)
+
+	discovery := kubeclient.Get(ctx).Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.FakedServerVersion = &version.Info{GitVersion: "v1.29.4-gke.1043002"}
+	discovery.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "eventing.knative.dev/v1",
+		APIResources: []metav1.APIResource{{Name: "brokers", Namespaced: true, Kind: "Broker"}},
+	}}
+	return ctx
+}
+
+func TestChecks(t *testing.T) {
+	ctx := testContext(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "knative-eventing"}},
+		deployment("eventing-controller", 2, 2),
+		deployment("eventing-webhook", 3, 1),
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "knative-eventing", Name: "config-features"},
+			Data:       map[string]string{"authentication-oidc": "enabled", "kreference-group": "disabled"},
+		},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "slow"}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{
+			Name:        "standard",
+			Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+		}},
+		node("node-1", true, false),
+		node("node-2", true, true),
+		node("node-3", false, false),
+	)
+
+	tests := map[string]struct {
+		check     feature.ShouldRun
+		shouldRun bool
+		reason    string
+	}{
+		"CRD installed": {
+			check:     CRDInstalled(brokers),
+			shouldRun: true,
+			reason:    "CRD brokers.eventing.knative.dev is installed and serves v1",
+		},
+		"CRD not installed": {
+			check:  CRDInstalled(triggers),
+			reason: "CRD triggers.eventing.knative.dev is not installed",
+		},
+		"CRD not established": {
+			check:  CRDInstalled(channels),
+			reason: "CRD channels.messaging.knative.dev is not established",
+		},
+		"CRD version not served": {
+			check:  CRDInstalled(oldBroker),
+			reason: "CRD brokers.eventing.knative.dev doesn't serve v1beta1, served versions: [v1]",
+		},
+		"API resource available": {
+			check:     APIResourceAvailable(brokers),
+			shouldRun: true,
+			reason:    "API resource eventing.knative.dev/v1, Resource=brokers is available",
+		},
+		"API resource not served": {
+			check:  APIResourceAvailable(triggers),
+			reason: "API resource triggers is not served by eventing.knative.dev/v1",
+		},
+		"API group version not served": {
+			check:  APIResourceAvailable(channels),
+			reason: "API group version messaging.knative.dev/v1 is not served",
+		},
+		"server version": {
+			check:     ServerVersionAtLeast("1.28"),
+			shouldRun: true,
+			reason:    "server version v1.29.4-gke.1043002 is at least 1.28",
+		},
+		"server version too old": {
+			check:  ServerVersionAtLeast("1.30"),
+			reason: "server version v1.29.4-gke.1043002 is older than 1.30",
+		},
+		"namespace exists": {
+			check:     NamespaceExists("knative-eventing"),
+			shouldRun: true,
+			reason:    "namespace knative-eventing exists",
+		},
+		"namespace doesn't exist": {
+			check:  NamespaceExists("cert-manager"),
+			reason: "namespace cert-manager doesn't exist",
+		},
+		"deployment ready": {
+			check:     DeploymentReady("knative-eventing", "eventing-controller"),
+			shouldRun: true,
+			reason:    "deployment knative-eventing/eventing-controller has 2/2 ready replicas",
+		},
+		"deployment not ready": {
+			check:  DeploymentReady("knative-eventing", "eventing-webhook"),
+			reason: "deployment knative-eventing/eventing-webhook has 3/3 updated and 1/3 ready replicas",
+		},
+		"deployment doesn't exist": {
+			check:  DeploymentReady("knative-eventing", "imc-controller"),
+			reason: "deployment knative-eventing/imc-controller doesn't exist",
+		},
+		"feature flag enabled": {
+			check:     KnativeFeatureFlagEnabled("config-features", "authentication-oidc"),
+			shouldRun: true,
+			reason:    "feature flag authentication-oidc is enabled in ConfigMap knative-eventing/config-features",
+		},
+		"feature flag disabled": {
+			check:  KnativeFeatureFlagEnabled("knative-eventing/config-features", "kreference-group"),
+			reason: "feature flag kreference-group is disabled in ConfigMap knative-eventing/config-features",
+		},
+		"feature flag not set": {
+			check:  KnativeFeatureFlagEnabled("config-features", "transport-encryption"),
+			reason: "feature flag transport-encryption is not set in ConfigMap knative-eventing/config-features",
+		},
+		"feature flags ConfigMap doesn't exist": {
+			check:  KnativeFeatureFlagEnabled("knative-serving/config-features", "multi-container"),
+			reason: "ConfigMap knative-serving/config-features doesn't exist",
+		},
+		"default storage class": {
+			check:     StorageClassDefault(),
+			shouldRun: true,
+			reason:    "StorageClass standard is the default",
+		},
+		"node count": {
+			check:     NodeCountAtLeast(1),
+			shouldRun: true,
+			reason:    "cluster has 1 ready schedulable nodes, at least 1",
+		},
+		"node count too low": {
+			check:  NodeCountAtLeast(2),
+			reason: "cluster has 1 ready schedulable nodes of 3, want at least 2",
+		},
+		"all of": {
+			check:     AllOf(NamespaceExists("knative-eventing"), CRDInstalled(brokers)),
+			shouldRun: true,
+			reason:    "namespace knative-eventing exists and CRD brokers.eventing.knative.dev is installed and serves v1",
+		},
+		"all of unsatisfied": {
+			check:  AllOf(NamespaceExists("knative-eventing"), NamespaceExists("cert-manager"), CRDInstalled(triggers)),
+			reason: "namespace cert-manager doesn't exist",
+		},
+		"any of": {
+			check:     AnyOf(NamespaceExists("cert-manager"), CRDInstalled(brokers)),
+			shouldRun: true,
+			reason:    "CRD brokers.eventing.knative.dev is installed and serves v1",
+		},
+		"any of unsatisfied": {
+			check:  AnyOf(NamespaceExists("cert-manager"), CRDInstalled(triggers)),
+			reason: "namespace cert-manager doesn't exist and CRD triggers.eventing.knative.dev is not installed",
+		},
+		"not": {
+			check:     Not(NamespaceExists("cert-manager")),
+			shouldRun: true,
+			reason:    "namespace cert-manager doesn't exist",
+		},
+		"not unsatisfied": {
+			check:  Not(NamespaceExists("knative-eventing")),
+			reason: "namespace knative-eventing exists",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := tc.check(ctx, t)
+			require.NoError(t, err)
+			require.Equal(t, feature.PrerequisiteResult{ShouldRun: tc.shouldRun, Reason: tc.reason}, result)
+		})
+	}
+}
+
+func TestNoDefaultStorageClass(t *testing.T) {
+	ctx := testContext(&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "slow"}})
+	result, err := StorageClassDefault()(ctx, t)
+	require.NoError(t, err)
+	require.Equal(t, feature.PrerequisiteResult{Reason: "no default StorageClass among [slow]"}, result)
+}
+
+func TestInvalidServerVersion(t *testing.T) {
+	_, err := ServerVersionAtLeast("latest")(testContext(), t)
+	require.Error(t, err)
+}
+
+func TestAnyOfErrors(t *testing.T) {
+	ctx := testContext(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "knative-eventing"}})
+
+	result, err := AnyOf(ServerVersionAtLeast("latest"), NamespaceExists("knative-eventing"))(ctx, t)
+	require.NoError(t, err, "a check is satisfied")
+	require.Equal(t, feature.PrerequisiteResult{ShouldRun: true, Reason: "namespace knative-eventing exists"}, result)
+
+	result, err = AnyOf(ServerVersionAtLeast("latest"), NamespaceExists("cert-manager"))(ctx, t)
+	require.Error(t, err, "no check is satisfied")
+	require.False(t, result.ShouldRun)
+}
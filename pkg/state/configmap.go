@@ -0,0 +1,80 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// ConfigMapKey is the key of the ConfigMap data holding the Store.
+	ConfigMapKey = "state.json"
+	// ConfigMapLabel marks the ConfigMaps holding a Store, which outlive the
+	// test on purpose.
+	ConfigMapLabel = "reconciler-test.knative.dev/state"
+)
+
+// NewConfigMapStore returns a Store saved to the ConfigMap namespace/name,
+// loading the content of the ConfigMap when it exists, for example from a
+// previous run in the same namespace.
+func NewConfigMapStore(ctx context.Context, client corev1client.ConfigMapsGetter, namespace, name string) (Store, error) {
+	cms := client.ConfigMaps(namespace)
+	ps := &persistentStore{
+		save: func(ctx context.Context, data []byte) error {
+			return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+				cm, err := cms.Get(ctx, name, metav1.GetOptions{})
+				if apierrors.IsNotFound(err) {
+					_, err = cms.Create(ctx, &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:   name,
+							Labels: map[string]string{ConfigMapLabel: "true"},
+						},
+						Data: map[string]string{ConfigMapKey: string(data)},
+					}, metav1.CreateOptions{})
+					return err
+				}
+				if err != nil {
+					return err
+				}
+				if cm.Data == nil {
+					cm.Data = make(map[string]string)
+				}
+				cm.Data[ConfigMapKey] = string(data)
+				_, err = cms.Update(ctx, cm, metav1.UpdateOptions{})
+				return err
+			})
+		},
+	}
+
+	cm, err := cms.Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get the state ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	if err == nil && cm.Data[ConfigMapKey] != "" {
+		if err := ps.KVStore.UnmarshalJSON([]byte(cm.Data[ConfigMapKey])); err != nil {
+			return nil, fmt.Errorf("failed to load the state ConfigMap %s/%s: %w", namespace, name, err)
+		}
+	}
+	return ps, nil
+}
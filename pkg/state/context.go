@@ -43,11 +43,38 @@ type fail interface {
 	Error(args ...interface{})
 }
 
+// fatal is defined to avoid circular dependency with the feature package.
+type fatal interface {
+	Fatal(args ...interface{})
+}
+
+// Get gets the key from the Store found in the context as a T.
+func Get[T any](ctx context.Context, key string) (T, error) {
+	var value T
+	err := FromContext(ctx).Get(ctx, key, &value)
+	return value, err
+}
+
+// MustGet gets the key from the Store found in the context as a T, or fails
+// the test immediately.
+func MustGet[T any](ctx context.Context, t fatal, key string) T {
+	value, err := Get[T](ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+// Set sets the key into the Store found in the context from the provided
+// value.
+func Set[T any](ctx context.Context, key string, value T) error {
+	return FromContext(ctx).Set(ctx, key, value)
+}
+
 // Get the string value from the kvstore from key.
 func GetStringOrFail(ctx context.Context, t fail, key string) string {
-	value := ""
-	state := FromContext(ctx)
-	if err := state.Get(ctx, key, &value); err != nil {
+	value, err := Get[string](ctx, key)
+	if err != nil {
 		t.Error(err)
 	}
 	return value
@@ -55,16 +82,14 @@ func GetStringOrFail(ctx context.Context, t fail, key string) string {
 
 // Get gets the key from the Store into the provided value
 func GetOrFail(ctx context.Context, t fail, key string, value interface{}) {
-	state := FromContext(ctx)
-	if err := state.Get(ctx, key, value); err != nil {
+	if err := FromContext(ctx).Get(ctx, key, value); err != nil {
 		t.Error(err)
 	}
 }
 
 // Set sets the key into the Store from the provided value
 func SetOrFail(ctx context.Context, t fail, key string, value interface{}) {
-	state := FromContext(ctx)
-	if err := state.Set(ctx, key, value); err != nil {
+	if err := Set(ctx, key, value); err != nil {
 		t.Error(err)
 	}
 }
@@ -0,0 +1,97 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type recordingT struct {
+	errors []string
+	fatal  bool
+}
+
+func (t *recordingT) Error(args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprint(args...))
+}
+
+func (t *recordingT) Fatal(args ...interface{}) {
+	t.Error(args...)
+	t.fatal = true
+}
+
+type sink struct {
+	URI  string
+	Port int
+}
+
+func TestGenericAccessors(t *testing.T) {
+	ctx := ContextWith(context.Background(), &KVStore{})
+
+	want := sink{URI: "http://sink", Port: 8080}
+	if err := Set(ctx, "sink", want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Get[sink](ctx, "sink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error("Unexpected value (-want, +got):", diff)
+	}
+
+	rt := &recordingT{}
+	if got := MustGet[sink](ctx, rt, "sink"); got != want || rt.fatal {
+		t.Errorf("MustGet() = %v, fatal %t", got, rt.fatal)
+	}
+
+	if _, err := Get[int](ctx, "sink"); err == nil {
+		t.Error("Get() of a struct as an int succeeded")
+	}
+	if _, err := Get[sink](ctx, "missing"); err == nil {
+		t.Error("Get() of a missing key succeeded")
+	}
+	MustGet[sink](ctx, rt, "missing")
+	if !rt.fatal {
+		t.Error("MustGet() of a missing key didn't fail")
+	}
+}
+
+func TestOrFailWrappers(t *testing.T) {
+	ctx := ContextWith(context.Background(), &KVStore{})
+	rt := &recordingT{}
+
+	SetOrFail(ctx, rt, "name", "receiver")
+	if got := GetStringOrFail(ctx, rt, "name"); got != "receiver" {
+		t.Errorf("GetStringOrFail() = %q", got)
+	}
+	var name string
+	GetOrFail(ctx, rt, "name", &name)
+	if name != "receiver" || len(rt.errors) != 0 {
+		t.Errorf("GetOrFail() = %q, errors %v", name, rt.errors)
+	}
+
+	GetStringOrFail(ctx, rt, "missing")
+	SetOrFail(ctx, rt, "func", func() {})
+	if len(rt.errors) != 2 || rt.fatal {
+		t.Errorf("Unexpected errors %v, fatal %t", rt.errors, rt.fatal)
+	}
+}
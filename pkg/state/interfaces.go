@@ -25,3 +25,16 @@ type Store interface {
 	// Set sets the key into the Store from the provided value
 	Set(ctx context.Context, key string, value interface{}) error
 }
+
+// KeyLister is implemented by the Stores that can list their keys.
+type KeyLister interface {
+	// Keys returns the sorted keys of the Store
+	Keys(ctx context.Context) []string
+}
+
+// Deleter is implemented by the Stores that can delete their keys.
+type Deleter interface {
+	// Delete deletes the key from the Store, it is not an error if the key
+	// doesn't exist
+	Delete(ctx context.Context, key string) error
+}
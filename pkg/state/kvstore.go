@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 )
 
@@ -28,6 +29,11 @@ type KVStore struct {
 	mux   sync.Mutex
 }
 
+var (
+	_ KeyLister = (*KVStore)(nil)
+	_ Deleter   = (*KVStore)(nil)
+)
+
 // Get retrieves and unmarshals the value from the map.
 func (cs *KVStore) Get(_ context.Context, key string, value interface{}) error {
 	cs.mux.Lock()
@@ -66,6 +72,44 @@ func (cs *KVStore) Set(_ context.Context, key string, value interface{}) error {
 	return nil
 }
 
+// Keys returns the sorted keys of the map.
+func (cs *KVStore) Keys(_ context.Context) []string {
+	cs.mux.Lock()
+	defer cs.mux.Unlock()
+
+	keys := make([]string, 0, len(cs.store))
+	for k := range cs.store {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Delete deletes the key from the map.
+func (cs *KVStore) Delete(_ context.Context, key string) error {
+	cs.mux.Lock()
+	defer cs.mux.Unlock()
+
+	delete(cs.store, key)
+	return nil
+}
+
 func (cs *KVStore) MarshalJSON() ([]byte, error) {
+	cs.mux.Lock()
+	defer cs.mux.Unlock()
+
 	return json.Marshal(cs.store)
 }
+
+// UnmarshalJSON replaces the map with the one serialized by MarshalJSON.
+func (cs *KVStore) UnmarshalJSON(b []byte) error {
+	cs.mux.Lock()
+	defer cs.mux.Unlock()
+
+	store := make(map[string]string)
+	if err := json.Unmarshal(b, &store); err != nil {
+		return err
+	}
+	cs.store = store
+	return nil
+}
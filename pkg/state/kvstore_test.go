@@ -89,3 +89,30 @@ func TestKVStore_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestKVStore_KeysDelete(t *testing.T) {
+	ctx := context.Background()
+	s := KVStore{}
+	if keys := s.Keys(ctx); len(keys) != 0 {
+		t.Errorf("Keys() = %v, want none", keys)
+	}
+	if err := s.Delete(ctx, "missing"); err != nil {
+		t.Error("Delete() of a missing key:", err)
+	}
+
+	for _, k := range []string{"foo", "bar", "baz"} {
+		if err := s.Set(ctx, k, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Delete(ctx, "baz"); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"bar", "foo"}, s.Keys(ctx)); diff != "" {
+		t.Error("Unexpected keys (-want, +got):", diff)
+	}
+	var v string
+	if err := s.Get(ctx, "baz", &v); err == nil {
+		t.Error("Get() of a deleted key succeeded")
+	}
+}
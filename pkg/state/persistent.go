@@ -0,0 +1,108 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// persistentStore is a KVStore saved, in the format of KVStore.MarshalJSON,
+// every time it is modified.
+type persistentStore struct {
+	KVStore
+	save func(ctx context.Context, data []byte) error
+	// mux serializes the modifications with their save, so that the last
+	// saved content is the current one.
+	mux sync.Mutex
+}
+
+func (ps *persistentStore) Set(ctx context.Context, key string, value interface{}) error {
+	ps.mux.Lock()
+	defer ps.mux.Unlock()
+
+	if err := ps.KVStore.Set(ctx, key, value); err != nil {
+		return err
+	}
+	return ps.persist(ctx)
+}
+
+func (ps *persistentStore) Delete(ctx context.Context, key string) error {
+	ps.mux.Lock()
+	defer ps.mux.Unlock()
+
+	if err := ps.KVStore.Delete(ctx, key); err != nil {
+		return err
+	}
+	return ps.persist(ctx)
+}
+
+func (ps *persistentStore) persist(ctx context.Context) error {
+	data, err := ps.KVStore.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return ps.save(ctx, data)
+}
+
+// NewFileStore returns a Store saved as JSON to the file at path, loading the
+// content of the file when it exists, for example from a previous run.
+func NewFileStore(path string) (Store, error) {
+	ps := &persistentStore{
+		save: func(_ context.Context, data []byte) error {
+			return writeFileAtomically(path, data)
+		},
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read the state file %s: %w", path, err)
+	}
+	if err == nil {
+		if err := ps.KVStore.UnmarshalJSON(data); err != nil {
+			return nil, fmt.Errorf("failed to load the state file %s: %w", path, err)
+		}
+	}
+	return ps, nil
+}
+
+// writeFileAtomically writes the file through a rename, so that a run that is
+// interrupted never leaves a partial file for the next one.
+func writeFileAtomically(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
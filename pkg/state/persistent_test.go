@@ -0,0 +1,126 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testPersistence sets values in the store created by newStore and checks
+// that a new store loads them.
+func testPersistence(t *testing.T, newStore func() (Store, error)) {
+	ctx := context.Background()
+
+	s, err := newStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := s.(KeyLister).Keys(ctx); len(keys) != 0 {
+		t.Fatalf("Keys() of a new store = %v", keys)
+	}
+	for k, v := range map[string]interface{}{
+		"name":       "receiver",
+		"group-1/id": 42,
+		"obsolete":   true,
+	} {
+		if err := s.Set(ctx, k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.(Deleter).Delete(ctx, "obsolete"); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := newStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"group-1/id", "name"}, loaded.(KeyLister).Keys(ctx)); diff != "" {
+		t.Error("Unexpected keys (-want, +got):", diff)
+	}
+	var id int
+	if err := loaded.Get(ctx, "group-1/id", &id); err != nil || id != 42 {
+		t.Errorf("Get() = %d, %v", id, err)
+	}
+
+	want, _ := json.Marshal(s)
+	got, _ := json.Marshal(loaded)
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Error("Unexpected JSON (-want, +got):", diff)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "feature.json")
+	testPersistence(t, func() (Store, error) {
+		return NewFileStore(path)
+	})
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(`{"group-1/id":"42","name":"\"receiver\""}`, string(b)); diff != "" {
+		t.Error("Unexpected file content (-want, +got):", diff)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileStore(path); err == nil {
+		t.Error("NewFileStore() of a corrupted file succeeded")
+	}
+}
+
+func TestConfigMapStore(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewClientset()
+	testPersistence(t, func() (Store, error) {
+		return NewConfigMapStore(ctx, client.CoreV1(), "test", "state-feature")
+	})
+
+	cm, err := client.CoreV1().ConfigMaps("test").Get(ctx, "state-feature", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.Labels[ConfigMapLabel] != "true" {
+		t.Errorf("Unexpected labels %v", cm.Labels)
+	}
+	if diff := cmp.Diff(`{"group-1/id":"42","name":"\"receiver\""}`, cm.Data[ConfigMapKey]); diff != "" {
+		t.Error("Unexpected ConfigMap content (-want, +got):", diff)
+	}
+
+	_, err = client.CoreV1().ConfigMaps("test").Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "corrupted"},
+		Data:       map[string]string{ConfigMapKey: "{"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewConfigMapStore(ctx, client.CoreV1(), "test", "corrupted"); err == nil {
+		t.Error("NewConfigMapStore() of a corrupted ConfigMap succeeded")
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ScopeSeparator separates the scope from the key in the underlying Store.
+const ScopeSeparator = "/"
+
+// Scope decorates the given context with a sub-store of the Store found in the
+// context, whose keys are prefixed with the scope, so that groups and step
+// libraries don't clash on key names. Scopes can be nested.
+func Scope(ctx context.Context, scope string) context.Context {
+	return ContextWith(ctx, Scoped(FromContext(ctx), scope))
+}
+
+// Scoped returns a sub-store of store whose keys are prefixed with the scope.
+// It panics if the scope contains the ScopeSeparator, since the keys of the
+// scope would clash with the keys of nested scopes.
+func Scoped(store Store, scope string) Store {
+	if strings.Contains(scope, ScopeSeparator) {
+		panic(fmt.Sprintf("state scope %q contains %q", scope, ScopeSeparator))
+	}
+	return &scopedStore{
+		prefix: scope + ScopeSeparator,
+		store:  store,
+	}
+}
+
+type scopedStore struct {
+	prefix string
+	store  Store
+}
+
+func (s *scopedStore) Get(ctx context.Context, key string, value interface{}) error {
+	return s.store.Get(ctx, s.prefix+key, value)
+}
+
+func (s *scopedStore) Set(ctx context.Context, key string, value interface{}) error {
+	return s.store.Set(ctx, s.prefix+key, value)
+}
+
+// Keys returns the keys of the scope, without the prefix, or none if the
+// underlying Store isn't a KeyLister.
+func (s *scopedStore) Keys(ctx context.Context) []string {
+	lister, ok := s.store.(KeyLister)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for _, k := range lister.Keys(ctx) {
+		if strings.HasPrefix(k, s.prefix) {
+			keys = append(keys, strings.TrimPrefix(k, s.prefix))
+		}
+	}
+	return keys
+}
+
+// Delete deletes the key of the scope, it fails if the underlying Store isn't
+// a Deleter.
+func (s *scopedStore) Delete(ctx context.Context, key string) error {
+	deleter, ok := s.store.(Deleter)
+	if !ok {
+		return fmt.Errorf("state store %T can't delete keys", s.store)
+	}
+	return deleter.Delete(ctx, s.prefix+key)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2026 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScope(t *testing.T) {
+	root := &KVStore{}
+	ctx := ContextWith(context.Background(), root)
+	group1 := Scope(ctx, "group-1")
+	group2 := Scope(ctx, "group-2")
+	nested := Scope(group1, "lib")
+
+	for ctx, uri := range map[context.Context]string{
+		ctx:    "http://root",
+		group1: "http://group-1",
+		group2: "http://group-2",
+		nested: "http://nested",
+	} {
+		if err := Set(ctx, "sinkURI", uri); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for ctx, want := range map[context.Context]string{
+		ctx:    "http://root",
+		group1: "http://group-1",
+		group2: "http://group-2",
+		nested: "http://nested",
+	} {
+		if got, err := Get[string](ctx, "sinkURI"); err != nil || got != want {
+			t.Errorf("Get() = %q, %v, want %q", got, err, want)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"group-1/lib/sinkURI", "group-1/sinkURI", "group-2/sinkURI", "sinkURI"}, root.Keys(ctx)); diff != "" {
+		t.Error("Unexpected root keys (-want, +got):", diff)
+	}
+	if diff := cmp.Diff([]string{"lib/sinkURI", "sinkURI"}, FromContext(group1).(KeyLister).Keys(group1)); diff != "" {
+		t.Error("Unexpected scope keys (-want, +got):", diff)
+	}
+
+	if err := FromContext(group1).(Deleter).Delete(group1, "sinkURI"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Get[string](group1, "sinkURI"); err == nil {
+		t.Error("Get() of a deleted key succeeded")
+	}
+	if got, err := Get[string](ctx, "sinkURI"); err != nil || got != "http://root" {
+		t.Errorf("Get() = %q, %v, the root key was modified", got, err)
+	}
+}
+
+func TestScopeWithSeparator(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Scope() with a separator didn't panic")
+		}
+	}()
+	Scope(ContextWith(context.Background(), &KVStore{}), "group/lib")
+}
@@ -41,5 +41,10 @@ func TestMain(m *testing.M) {
 	global = environment.NewStandardGlobalEnvironment()
 
 	// Run the tests.
-	os.Exit(m.Run())
+	code := m.Run()
+
+	// Delete the namespaces of the --rekt.namespace-pool that weren't used.
+	environment.DrainNamespacePool(global)
+
+	os.Exit(code)
 }